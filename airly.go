@@ -1,12 +1,18 @@
 package airly
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,10 +29,34 @@ type HTTPDoer interface {
 type Client struct {
 	// HTTP client used to communicate with the API.
 	client HTTPDoer
+	// doer is client wrapped in middlewares.
+	doer        HTTPDoer
+	middlewares []Middleware
 
 	apiKey   string
 	baseURL  *url.URL
-	language string
+	language Language
+
+	cache     Cache
+	cacheOpts CacheOptions
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+
+	instrumentation Instrumentation
+
+	// validators stores responses used for conditional requests.
+	validators Cache
+
+	keys *KeyPool
+
+	preferEmbeddedMeta bool
+
+	coalesce bool
+	inflight group
+
+	// mu guards revalidating, the cache keys refreshed in the background.
+	mu           sync.Mutex
+	revalidating map[string]bool
 
 	Installation *InstallationService
 	Measurement  *MeasurementService
@@ -46,12 +76,14 @@ func NewClient(client HTTPDoer, apiKey string) (*Client, error) {
 
 	c := &Client{
 		client: client,
+		doer:   client,
 		apiKey: apiKey,
 		baseURL: &url.URL{
 			Host:   "airapi.airly.eu",
 			Scheme: "https",
 			Path:   "/v2/",
 		},
+		now: time.Now,
 	}
 
 	c.Installation = &InstallationService{client: c}
@@ -61,13 +93,26 @@ func NewClient(client HTTPDoer, apiKey string) (*Client, error) {
 	return c, nil
 }
 
-// Language is used to set a different language for textual content returned by Airly API.
+// Language is used to set a different language for textual content returned by Airly API
+// and for messages generated by the package. It modifies the client, so it should be called
+// before the client is shared; use WithLanguage to change the language of a single request.
 // https://developer.airly.eu/docs#general.language
-func (c *Client) Language(lang string) *Client {
+func (c *Client) Language(lang Language) *Client {
 	c.language = lang
 	return c
 }
 
+// BaseURL is used to point the client at a different API host, e.g. a proxy
+// or a fake server. The URL should include the API version path ("/v2/").
+func (c *Client) BaseURL(u *url.URL) *Client {
+	base := *u
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	c.baseURL = &base
+	return c
+}
+
 // Violation represents an error that the requested value is invalid.
 type Violation struct {
 	Parameter     string `json:"parameter"`
@@ -85,13 +130,15 @@ type Error struct {
 	ErrorCode string  `json:"errorCode"`
 	Message   string  `json:"message"`
 	Details   Details `json:"details"`
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"-"`
 }
 
 func (e Error) Error() string {
 	return e.Message
 }
 
-func (c *Client) decodeError(resp *http.Response) error {
+func (c *Client) decodeError(resp *http.Response, lang Language) error {
 	var e Error
 
 	err := json.NewDecoder(resp.Body).Decode(&e)
@@ -99,8 +146,9 @@ func (c *Client) decodeError(resp *http.Response) error {
 		return fmt.Errorf("decode response: %w", err)
 	}
 
+	e.StatusCode = resp.StatusCode
 	if e.Message == "" {
-		e.Message = fmt.Sprintf(
+		e.Message = lang.sprintf(
 			"airly: unexpected HTTP %d %s (empty error)",
 			resp.StatusCode,
 			http.StatusText(resp.StatusCode),
@@ -110,8 +158,49 @@ func (c *Client) decodeError(resp *http.Response) error {
 	return e
 }
 
+// InvalidParam describes a single query parameter rejected by local validation.
+type InvalidParam struct {
+	Parameter string
+	Message   string
+
+	// format and args of Message, used to translate it.
+	format string
+	args   []interface{}
+}
+
+func newInvalidParam(param, format string, args ...interface{}) InvalidParam {
+	return InvalidParam{
+		Parameter: param,
+		Message:   fmt.Sprintf(format, args...),
+		format:    format,
+		args:      args,
+	}
+}
+
+// ValidationError is returned when query opts are rejected before any request
+// is sent. It lists every invalid parameter, not only the first one.
+type ValidationError struct {
+	Params []InvalidParam
+
+	lang Language
+}
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Params))
+	for _, p := range e.Params {
+		msgs = append(msgs, p.Parameter+": "+p.Message)
+	}
+	return e.lang.sprintf("airly: invalid opts: ") + strings.Join(msgs, "; ")
+}
+
+// UnlimitedResults can be passed to MaxResults to return all matching results.
+const UnlimitedResults = -1
+
+// urlQuery is shared by all opts types. It is not safe for concurrent
+// modification, opts meant to be reused across goroutines should be cloned.
 type urlQuery struct {
-	opts url.Values
+	opts    url.Values
+	invalid []InvalidParam
 }
 
 // NewURLQuery creates new query params builder.
@@ -121,38 +210,142 @@ func NewURLQuery() *urlQuery {
 	}
 }
 
+// clone returns a deep copy of q that can be modified independently.
+func (q *urlQuery) clone() *urlQuery {
+	c := &urlQuery{
+		opts:    make(url.Values, len(q.opts)),
+		invalid: append([]InvalidParam(nil), q.invalid...),
+	}
+	for k, v := range q.opts {
+		c.opts[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// Validate reports all problems found in the query params.
+func (q *urlQuery) Validate() error {
+	return q.validate()
+}
+
+// validate reports all problems found in the query params, including
+// the required params that are not set, e.g. in a zero value of opts.
+func (q *urlQuery) validate(required ...string) error {
+	params := append([]InvalidParam(nil), q.invalid...)
+	for _, param := range required {
+		if _, ok := q.opts[param]; !ok {
+			params = append(params, newInvalidParam(param, "is required"))
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return ValidationError{Params: params}
+}
+
+// set sets the query param key to value.
+func (q *urlQuery) set(key, value string) {
+	if q.opts == nil {
+		q.opts = url.Values{}
+	}
+	q.opts.Set(key, value)
+}
+
+// check records a message formatted from format and args as a problem of
+// param, or clears a previously recorded one when ok is true, so that
+// calling a setter again fixes the query.
+func (q *urlQuery) check(param string, ok bool, format string, args ...interface{}) {
+	for i, p := range q.invalid {
+		if p.Parameter == param {
+			q.invalid = append(q.invalid[:i], q.invalid[i+1:]...)
+			break
+		}
+	}
+	if !ok {
+		q.invalid = append(q.invalid, newInvalidParam(param, format, args...))
+	}
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
 func (q *urlQuery) setLocation(lat, lng float64) *urlQuery {
-	q.opts.Set("lat", fmt.Sprint(lat))
-	q.opts.Set("lng", fmt.Sprint(lng))
+	q.check("lat", isFinite(lat) && lat >= -90 && lat <= 90,
+		"%v is not a latitude in range [-90, 90]", lat)
+	q.check("lng", isFinite(lng) && lng >= -180 && lng <= 180,
+		"%v is not a longitude in range [-180, 180]", lng)
+	q.set("lat", fmt.Sprint(lat))
+	q.set("lng", fmt.Sprint(lng))
 	return q
 }
 
 func (q *urlQuery) setInstallationID(id int64) *urlQuery {
-	q.opts.Set("installationId", fmt.Sprint(id))
+	q.set("installationId", fmt.Sprint(id))
 	return q
 }
 
 func (q *urlQuery) setMaxDistance(km float64) *urlQuery {
-	q.opts.Set("maxDistanceKM", fmt.Sprint(km))
+	q.check("maxDistanceKM", isFinite(km) && km >= 0,
+		"%v is not a non-negative distance", km)
+	q.set("maxDistanceKM", fmt.Sprint(km))
 	return q
 }
 
-func (q *urlQuery) setMaxResults(limit float64) *urlQuery {
-	q.opts.Set("maxResults", fmt.Sprint(limit))
+func (q *urlQuery) setMaxResults(limit int) *urlQuery {
+	q.check("maxResults", limit == UnlimitedResults || limit > 0,
+		"%d is neither a positive number nor %d (unlimited)", limit, UnlimitedResults)
+	q.set("maxResults", strconv.Itoa(limit))
 	return q
 }
 
 func (q *urlQuery) setIncludeWind(wind bool) *urlQuery {
-	q.opts.Set("includeWind", strconv.FormatBool(wind))
+	q.set("includeWind", strconv.FormatBool(wind))
 	return q
 }
 
-func (q *urlQuery) setIndexType(index indexType) *urlQuery {
-	q.opts.Set("indexType", string(index))
+func (q *urlQuery) setIndexType(index IndexKind) *urlQuery {
+	q.set("indexType", string(index))
 	return q
 }
 
-func (c *Client) get(path string, params url.Values, result interface{}) error {
+func (c *Client) get(ctx context.Context, op Operation, path string, params url.Values, result interface{}) (resp *Response, err error) {
+	if c.instrumentation != nil {
+		var end func(OperationResult)
+		ctx, end = c.instrumentation.StartOperation(ctx, op.withParams(params))
+		start := time.Now()
+		defer func() { end(operationResult(resp, err, time.Since(start))) }()
+	}
+
+	req, err := c.newRequest(ctx, path, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.coalesce {
+		return c.coalescedGet(ctx, req, result)
+	}
+	return c.load(req, result)
+}
+
+// load returns the result from cache or sends the request.
+func (c *Client) load(req *http.Request, result interface{}) (*Response, error) {
+	if c.cache != nil {
+		return c.cachedGet(req, result)
+	}
+
+	body, resp, err := c.do(req)
+	if err != nil {
+		return resp, err
+	}
+	return resp, decode(body, result)
+}
+
+func (c *Client) newRequest(ctx context.Context, path string, params url.Values) (*http.Request, error) {
+	lang := c.requestLanguage(ctx)
+	if err := checkLanguage(lang); err != nil {
+		return nil, localize(err, c.language)
+	}
+
 	u := c.baseURL.ResolveReference(
 		&url.URL{
 			Path:     path,
@@ -160,30 +353,63 @@ func (c *Client) get(path string, params url.Values, result interface{}) error {
 		},
 	)
 
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return fmt.Errorf("http.NewRequest: %w", err)
+		return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
 	}
 
 	req.Header.Add("apiKey", c.apiKey)
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Type", "application/json")
 
-	if c.language != "" {
-		req.Header.Add("Accept-Language", c.language)
+	if lang != "" {
+		req.Header.Add("Accept-Language", string(lang))
 	}
 
-	resp, err := c.client.Do(req)
+	return req, nil
+}
+
+// do sends the request and returns the body of a successful response.
+// The Response is returned for error responses too. A 304 response
+// to a conditional request is successful and returns the stored body.
+func (c *Client) do(req *http.Request) ([]byte, *Response, error) {
+	if c.keys != nil {
+		return c.doWithKeys(req)
+	}
+	return c.send(req)
+}
+
+// send sends the request once with the api key set on it.
+func (c *Client) send(req *http.Request) ([]byte, *Response, error) {
+	req, prev, revalidating := c.conditional(req)
+	start := time.Now()
+	resp, err := c.doer.Do(req)
 	if err != nil {
-		return fmt.Errorf("doer.Do: %w", err)
+		return nil, nil, fmt.Errorf("doer.Do: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return c.decodeError(resp)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read response: %w", err)
+	}
+	notModified := resp.StatusCode == http.StatusNotModified && revalidating
+	if notModified {
+		body = prev.Body
+	}
+	r := newResponse(resp, body, time.Since(start))
+
+	if resp.StatusCode != http.StatusOK && !notModified {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return nil, r, c.decodeError(resp, Language(req.Header.Get("Accept-Language")))
 	}
+	c.storeValidators(req, resp, body, prev)
+
+	return body, r, nil
+}
 
-	err = json.NewDecoder(resp.Body).Decode(result)
+func decode(body []byte, result interface{}) error {
+	err := json.Unmarshal(body, result)
 	if err != nil {
 		return fmt.Errorf("decode response: %w", err)
 	}
@@ -1,7 +1,9 @@
 package airly
 
 import (
+	"errors"
 	"log"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -38,3 +40,97 @@ func testFormValues(t *testing.T, r *http.Request, values url.Values) {
 		t.Errorf("request parameters: %v, want %v", got, values)
 	}
 }
+
+func TestURLQuery_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  *urlQuery
+		params []string
+	}{
+		{
+			name:  "valid",
+			query: NewURLQuery().setLocation(50.06, 19.94).setMaxDistance(3).setMaxResults(-1),
+		},
+		{
+			name:   "out of range location",
+			query:  NewURLQuery().setLocation(200, -180.5),
+			params: []string{"lat", "lng"},
+		},
+		{
+			name:   "not finite",
+			query:  NewURLQuery().setLocation(math.NaN(), math.Inf(1)).setMaxDistance(math.Inf(1)),
+			params: []string{"lat", "lng", "maxDistanceKM"},
+		},
+		{
+			name:   "negative distance and results",
+			query:  NewURLQuery().setMaxDistance(-1).setMaxResults(-5),
+			params: []string{"maxDistanceKM", "maxResults"},
+		},
+		{
+			name:   "zero results",
+			query:  NewURLQuery().setMaxResults(0),
+			params: []string{"maxResults"},
+		},
+		{
+			name:  "fixed by later call",
+			query: NewURLQuery().setMaxResults(0).setMaxResults(5),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if len(tt.params) == 0 {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			var verr ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate returned %v, want ValidationError", err)
+			}
+			var got []string
+			for _, p := range verr.Params {
+				got = append(got, p.Parameter)
+			}
+			if !reflect.DeepEqual(got, tt.params) {
+				t.Errorf("Validate reported %v, want %v", got, tt.params)
+			}
+		})
+	}
+}
+
+func TestURLQuery_clone(t *testing.T) {
+	base := NewNearestInstallationOpts(50.06, 19.94).MaxResults(0)
+	c := base.Clone().MaxResults(UnlimitedResults).MaxDistance(5)
+
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate clone: %v", err)
+	}
+	if err := base.Validate(); err == nil {
+		t.Error("Validate base: clone fixed base opts")
+	}
+	if _, ok := base.opts["maxDistanceKM"]; ok {
+		t.Error("clone modified base opts")
+	}
+	if got := c.opts.Get("maxResults"); got != "-1" {
+		t.Errorf("maxResults = %q, want -1", got)
+	}
+}
+
+func TestClient_BaseURL(t *testing.T) {
+	client, err := NewClient(nil, "apiKey")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	u, _ := url.Parse("http://localhost:8080/v2")
+	client.BaseURL(u)
+
+	got := client.baseURL.ResolveReference(&url.URL{Path: "meta/indexes"}).String()
+	if want := "http://localhost:8080/v2/meta/indexes"; got != want {
+		t.Errorf("resolved URL %q, want %q", got, want)
+	}
+	if u.Path != "/v2" {
+		t.Errorf("BaseURL modified its argument: %q", u.Path)
+	}
+}
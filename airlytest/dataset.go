@@ -0,0 +1,230 @@
+package airlytest
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/lsjurczak/go-airly"
+)
+
+// Dataset is the in-memory data served by a Server.
+// Texts are written in English and translated on the fly
+// according to the Accept-Language request header.
+type Dataset struct {
+	Installations []airly.Installation
+	// Measurements holds time series by installation ID.
+	Measurements     map[int64]airly.Measurement
+	Indexes          []airly.IndexType
+	MeasurementTypes []airly.MeasurementType
+	// Translations maps a language to translations of English texts.
+	Translations map[string]map[string]string
+}
+
+// Epoch is the till date time of the current measurement in DefaultDataset.
+var Epoch = time.Date(2020, 5, 7, 15, 0, 0, 0, time.UTC)
+
+// DefaultDataset returns a dataset of a few installations in Poland with
+// 24 hours of history and forecast around Epoch.
+func DefaultDataset() Dataset {
+	installations := []airly.Installation{
+		{
+			ID:        9599,
+			Location:  airly.Location{Latitude: 52.287217, Longitude: 21.108757},
+			Address:   airly.Address{Country: "Poland", City: "Ząbki", Street: "Piłsudskiego", Number: "35", DisplayAddress1: "Ząbki", DisplayAddress2: "Piłsudskiego"},
+			Elevation: 85.02,
+			Airly:     true,
+			Sponsor:   airly.Sponsor{ID: 371, Name: "Powiat Wołomiński", Description: "Airly Sensor's sponsor", Logo: "https://cdn.airly.eu/logo/logo.jpg"},
+		},
+		{
+			ID:        8077,
+			Location:  airly.Location{Latitude: 50.062006, Longitude: 19.940984},
+			Address:   airly.Address{Country: "Poland", City: "Kraków", Street: "Mikołajska", Number: "4", DisplayAddress1: "Kraków", DisplayAddress2: "Mikołajska"},
+			Elevation: 220.38,
+			Airly:     true,
+			Sponsor:   airly.Sponsor{ID: 489, Name: "Chatham Financial", Description: "Airly Sensor's sponsor", Logo: "https://cdn.airly.eu/logo/logo.jpg"},
+		},
+		{
+			ID:        8078,
+			Location:  airly.Location{Latitude: 50.069, Longitude: 19.926},
+			Address:   airly.Address{Country: "Poland", City: "Kraków", Street: "Karmelicka", Number: "55", DisplayAddress1: "Kraków", DisplayAddress2: "Karmelicka"},
+			Elevation: 210.5,
+			Airly:     true,
+			Sponsor:   airly.Sponsor{ID: 489, Name: "Chatham Financial", Description: "Airly Sensor's sponsor", Logo: "https://cdn.airly.eu/logo/logo.jpg"},
+		},
+		{
+			ID:        2139,
+			Location:  airly.Location{Latitude: 50.057678, Longitude: 19.926189},
+			Address:   airly.Address{Country: "Poland", City: "Kraków", Street: "Dietla", Number: "", DisplayAddress1: "Kraków", DisplayAddress2: "Dietla"},
+			Elevation: 207,
+			Airly:     false,
+			Sponsor:   airly.Sponsor{ID: 11, Name: "GIOŚ", Description: "Government data"},
+		},
+	}
+
+	measurements := map[int64]airly.Measurement{}
+	for i, inst := range installations {
+		measurements[inst.ID] = series(Epoch, float64(i))
+	}
+
+	return Dataset{
+		Installations:    installations,
+		Measurements:     measurements,
+		Indexes:          indexes(),
+		MeasurementTypes: measurementTypes(),
+		Translations:     translations(),
+	}
+}
+
+// series generates a daily cycle of measurements, shifted by phase hours.
+func series(till time.Time, phase float64) airly.Measurement {
+	at := func(h int) airly.Data {
+		x := 2 * math.Pi * (float64(h) + phase) / 24
+		pm25 := round(20 + 15*math.Sin(x))
+		pm10 := round(pm25 * 1.4)
+		d := airly.Data{
+			FromDateTime: till.Add(time.Duration(h-1) * time.Hour),
+			TillDateTime: till.Add(time.Duration(h) * time.Hour),
+			Values: []airly.Value{
+				{Name: "PM1", Value: round(pm25 * 0.7)},
+				{Name: "PM25", Value: pm25},
+				{Name: "PM10", Value: pm10},
+				{Name: "PRESSURE", Value: round(1012 + 3*math.Cos(x))},
+				{Name: "HUMIDITY", Value: round(60 + 20*math.Cos(x))},
+				{Name: "TEMPERATURE", Value: round(14 - 6*math.Cos(x))},
+				{Name: "WIND_SPEED", Value: round(12 + 5*math.Sin(x/2))},
+				{Name: "WIND_BEARING", Value: round(math.Mod(180+90*math.Sin(x), 360))},
+			},
+			Indexes: []airly.Index{caqi("AIRLY_CAQI", pm25, pm10), caqi("CAQI", pm25, pm10)},
+			Standards: []airly.Standard{
+				{Name: "WHO", Pollutant: "PM25", Limit: 25, Percent: round(pm25 / 25 * 100), Averaging: "24h"},
+				{Name: "WHO", Pollutant: "PM10", Limit: 50, Percent: round(pm10 / 50 * 100), Averaging: "24h"},
+			},
+		}
+		return d
+	}
+
+	var m airly.Measurement
+	m.Current = at(0)
+	for h := -23; h < 0; h++ {
+		m.History = append(m.History, at(h))
+	}
+	for h := 1; h <= 24; h++ {
+		d := at(h)
+		d.Values = d.Values[1:3]
+		d.Standards = nil
+		m.Forecast = append(m.Forecast, d)
+	}
+	return m
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+type level struct {
+	name        string
+	min, max    float64
+	description string
+	advice      string
+	color       string
+}
+
+var caqiLevels = []level{
+	{"VERY_LOW", 0, 25, "Great air here today!", "Perfect air for exercising! Go for it!", "#6BC926"},
+	{"LOW", 25, 50, "Air is quite good.", "Take a deep breath. Today, you can. ;)", "#D1CF1E"},
+	{"MEDIUM", 50, 75, "Well... It's been better.", "Limit outdoor activity today.", "#EFBB0F"},
+	{"HIGH", 75, 100, "Air is bad today!", "Avoid exercising outdoors!", "#EF7120"},
+	{"VERY_HIGH", 100, math.Inf(1), "Air is very bad!", "Stay at home and close the windows!", "#EF2A36"},
+}
+
+// caqi calculates the hourly Common Air Quality Index from PM concentrations.
+func caqi(name string, pm25, pm10 float64) airly.Index {
+	sub := func(c float64, grid []float64) float64 {
+		for i := 1; i < len(grid); i++ {
+			if c <= grid[i] {
+				return 25*float64(i-1) + 25*(c-grid[i-1])/(grid[i]-grid[i-1])
+			}
+		}
+		n := len(grid) - 1
+		return 25*float64(n-1) + 25*(c-grid[n-1])/(grid[n]-grid[n-1])
+	}
+	v := round(math.Max(sub(pm25, []float64{0, 15, 30, 55, 110}), sub(pm10, []float64{0, 25, 50, 90, 180})))
+	l := caqiLevels[len(caqiLevels)-1]
+	for _, cl := range caqiLevels {
+		if v < cl.max {
+			l = cl
+			break
+		}
+	}
+	return airly.Index{
+		Name:        name,
+		Value:       v,
+		Level:       l.name,
+		Description: l.description,
+		Advice:      l.advice,
+		Color:       l.color,
+	}
+}
+
+func indexes() []airly.IndexType {
+	var levels []airly.Level
+	for _, l := range caqiLevels {
+		// Like the Airly API, the open-ended top level has no max value.
+		values, max := trimFloat(l.min)+"+", 0.0
+		if !math.IsInf(l.max, 1) {
+			values, max = trimFloat(l.min)+"-"+trimFloat(l.max), l.max
+		}
+		levels = append(levels, airly.Level{
+			MinValue:    l.min,
+			MaxValue:    max,
+			Values:      values,
+			Level:       l.name,
+			Description: l.description,
+			Color:       l.color,
+		})
+	}
+	return []airly.IndexType{
+		{Name: "AIRLY_CAQI", Levels: levels},
+		{Name: "CAQI", Levels: levels},
+	}
+}
+
+func trimFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func measurementTypes() []airly.MeasurementType {
+	return []airly.MeasurementType{
+		{Name: "PM1", Label: "PM1", Unit: "µg/m³"},
+		{Name: "PM25", Label: "PM2.5", Unit: "µg/m³"},
+		{Name: "PM10", Label: "PM10", Unit: "µg/m³"},
+		{Name: "TEMPERATURE", Label: "Temperature", Unit: "°C"},
+		{Name: "HUMIDITY", Label: "Humidity", Unit: "%"},
+		{Name: "PRESSURE", Label: "Pressure", Unit: "hPa"},
+		{Name: "WIND_SPEED", Label: "Wind speed", Unit: "km/h"},
+		{Name: "WIND_BEARING", Label: "Wind bearing", Unit: "°"},
+	}
+}
+
+func translations() map[string]map[string]string {
+	return map[string]map[string]string{
+		"pl": {
+			"Great air here today!":                  "Świetne powietrze!",
+			"Perfect air for exercising! Go for it!": "Idealne powietrze na sport! Do dzieła!",
+			"Air is quite good.":                     "Powietrze jest całkiem dobre.",
+			"Take a deep breath. Today, you can. ;)": "Weź głęboki oddech. Dziś możesz. ;)",
+			"Well... It's been better.":              "Bywało lepiej...",
+			"Limit outdoor activity today.":          "Ogranicz dziś aktywność na zewnątrz.",
+			"Air is bad today!":                      "Dziś powietrze jest złe!",
+			"Avoid exercising outdoors!":             "Unikaj sportu na zewnątrz!",
+			"Air is very bad!":                       "Powietrze jest bardzo złe!",
+			"Stay at home and close the windows!":    "Zostań w domu i zamknij okna!",
+			"Temperature":                            "Temperatura",
+			"Humidity":                               "Wilgotność",
+			"Pressure":                               "Ciśnienie",
+			"Wind speed":                             "Prędkość wiatru",
+			"Wind bearing":                           "Kierunek wiatru",
+		},
+	}
+}
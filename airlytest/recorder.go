@@ -0,0 +1,221 @@
+package airlytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/lsjurczak/go-airly"
+)
+
+// Mode determines how a Recorder handles requests.
+type Mode int
+
+const (
+	// ModeReplay serves requests from the cassette only and fails
+	// requests without a recorded interaction.
+	ModeReplay Mode = iota
+	// ModeRecord sends every request and records a new cassette.
+	ModeRecord
+	// ModeRecordMissing replays recorded interactions and sends
+	// and records only the requests missing in the cassette.
+	ModeRecordMissing
+)
+
+// ErrNoInteraction is returned in ModeReplay for a request that
+// has no recorded interaction.
+var ErrNoInteraction = errors.New("airlytest: no recorded interaction")
+
+// redacted replaces the value of the apiKey header in cassettes.
+const redacted = "REDACTED"
+
+// Cassette is the on-disk format of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request/response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest holds the request fields used for matching.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query"`
+	Header http.Header `json:"header"`
+}
+
+// RecordedResponse holds a response to replay.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an airly.HTTPDoer that records interactions with the Airly API
+// to a cassette file and replays them, making tests deterministic.
+type Recorder struct {
+	doer airly.HTTPDoer
+	path string
+	mode Mode
+
+	mu       sync.Mutex
+	cassette Cassette
+	replayed map[int]bool
+	changed  bool
+}
+
+var _ airly.HTTPDoer = (*Recorder)(nil)
+
+// NewRecorder creates a Recorder for the cassette file at path.
+// The doer sends requests that are recorded, it is not used in ModeReplay.
+// The cassette must exist in ModeReplay.
+func NewRecorder(path string, mode Mode, doer airly.HTTPDoer) (*Recorder, error) {
+	r := &Recorder{
+		doer:     doer,
+		path:     path,
+		mode:     mode,
+		replayed: map[int]bool{},
+	}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) && mode == ModeRecordMissing {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	if err := json.Unmarshal(b, &r.cassette); err != nil {
+		return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Do replays or records the request, depending on the mode.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	rec := recordRequest(req)
+
+	r.mu.Lock()
+	if r.mode != ModeRecord {
+		if i, ok := r.match(rec); ok {
+			r.replayed[i] = true
+			resp := r.cassette.Interactions[i].Response
+			r.mu.Unlock()
+			return resp.response(req), nil
+		}
+	}
+	r.mu.Unlock()
+
+	if r.mode == ModeReplay {
+		return nil, fmt.Errorf("%w for %s %s?%s", ErrNoInteraction, rec.Method, rec.Path, rec.Query)
+	}
+
+	resp, err := r.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	recResp := RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       string(body),
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{Request: rec, Response: recResp})
+	r.replayed[len(r.cassette.Interactions)-1] = true
+	r.changed = true
+	r.mu.Unlock()
+
+	return recResp.response(req), nil
+}
+
+// match returns the first interaction matching rec that was not replayed yet,
+// or the last matching one if all of them were.
+func (r *Recorder) match(rec RecordedRequest) (int, bool) {
+	last := -1
+	for i, in := range r.cassette.Interactions {
+		if in.Request.Method != rec.Method || in.Request.Path != rec.Path || in.Request.Query != rec.Query {
+			continue
+		}
+		if !r.replayed[i] {
+			return i, true
+		}
+		last = i
+	}
+	return last, last >= 0
+}
+
+// Save writes the cassette to its file if any interaction was recorded.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.changed {
+		return nil
+	}
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cassette: %w", err)
+	}
+	if err := os.WriteFile(r.path, b, 0o644); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	r.changed = false
+	return nil
+}
+
+func recordRequest(req *http.Request) RecordedRequest {
+	header := req.Header.Clone()
+	if header.Get("apiKey") != "" {
+		header.Set("apiKey", redacted)
+	}
+	return RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  normalizeQuery(req.URL.RawQuery),
+		Header: header,
+	}
+}
+
+// normalizeQuery sorts query params by key and value.
+func normalizeQuery(raw string) string {
+	q, err := url.ParseQuery(raw)
+	if err != nil {
+		return raw
+	}
+	for _, v := range q {
+		sort.Strings(v)
+	}
+	return q.Encode()
+}
+
+func (rr RecordedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.StatusCode, http.StatusText(rr.StatusCode)),
+		StatusCode:    rr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rr.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewBufferString(rr.Body)),
+		ContentLength: int64(len(rr.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,110 @@
+package airlytest
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lsjurczak/go-airly"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := os.MkdirTemp("", "airlytest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cassette := filepath.Join(dir, "cassette.json")
+
+	s := NewServer(DefaultDataset())
+	defer s.Close()
+	u, _ := url.Parse(s.URL)
+
+	newClient := func(r *Recorder) *airly.Client {
+		c, err := airly.NewClient(r, APIKey)
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		return c.BaseURL(u)
+	}
+	opt := airly.NewNearestInstallationOpts(50.062, 19.94).MaxResults(2).MaxDistance(5)
+
+	rec, err := NewRecorder(cassette, ModeRecord, s.srv.Client())
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	want, err := newClient(rec).Installation.Nearest(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("record Installation.Nearest: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	b, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), APIKey) {
+		t.Error("cassette contains the api key")
+	}
+
+	s.Close()
+	rec, err = NewRecorder(cassette, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	client := newClient(rec)
+
+	// Params in a different order match the recorded query.
+	got, err := client.Installation.Nearest(context.Background(), airly.NewNearestInstallationOpts(50.062, 19.94).MaxDistance(5).MaxResults(2))
+	if err != nil {
+		t.Fatalf("replay Installation.Nearest: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed %+v, want %+v", got, want)
+	}
+
+	_, err = client.Installation.ByID(context.Background(), 8077)
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("unmatched request returned %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestRecorder_recordMissing(t *testing.T) {
+	dir, err := os.MkdirTemp("", "airlytest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cassette := filepath.Join(dir, "cassette.json")
+
+	s := NewServer(DefaultDataset())
+	defer s.Close()
+	client := func(r *Recorder) *airly.Client {
+		c, _ := airly.NewClient(r, APIKey)
+		u, _ := url.Parse(s.URL)
+		return c.BaseURL(u)
+	}
+
+	for i := 0; i < 2; i++ {
+		rec, err := NewRecorder(cassette, ModeRecordMissing, s.srv.Client())
+		if err != nil {
+			t.Fatalf("NewRecorder: %v", err)
+		}
+		if _, err := client(rec).Meta.Indexes(context.Background()); err != nil {
+			t.Fatalf("Meta.Indexes: %v", err)
+		}
+		if err := rec.Save(); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	if got := s.Requests(); got != 1 {
+		t.Errorf("server received %d requests, want 1", got)
+	}
+}
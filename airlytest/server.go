@@ -0,0 +1,522 @@
+// Package airlytest provides a fake Airly API server and a record/replay
+// transport for integration tests that need to run offline.
+package airlytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lsjurczak/go-airly"
+)
+
+// APIKey is the key accepted by a Server unless configured otherwise.
+const APIKey = "airlytest"
+
+// Default query params of the Airly API.
+const (
+	defaultMaxDistanceKM = 3
+	defaultMaxResults    = 1
+	defaultIndexType     = "AIRLY_CAQI"
+	// pointMaxDistanceKM is the radius in which measurements/point
+	// looks for an installation.
+	pointMaxDistanceKM = 3
+)
+
+// Failure describes an error response injected by Server.Fail.
+type Failure struct {
+	// Status is the HTTP status code, 500 if zero.
+	Status int
+	// ErrorCode and Message are encoded as an Airly error body.
+	// Both empty result in an empty body.
+	ErrorCode string
+	Message   string
+	// Delay postpones the response, e.g. to trigger client timeouts.
+	Delay time.Duration
+	// CloseConnection drops the connection without a response.
+	CloseConnection bool
+	// Times is the number of requests the failure applies to,
+	// zero means until ClearFailures is called.
+	Times int
+}
+
+type failure struct {
+	path string
+	Failure
+}
+
+// Server is a fake Airly v2 API server. It serves a Dataset under
+// the /v2/ path, checks the apiKey header, sends rate limit headers and
+// localizes textual content according to the Accept-Language header.
+type Server struct {
+	// URL is the base URL of the API, including the /v2/ path.
+	URL string
+
+	srv *httptest.Server
+
+	mu         sync.Mutex
+	data       Dataset
+	keys       map[string]bool
+	dailyLimit int
+	used       int
+	requests   int
+	failures   []*failure
+}
+
+// NewServer starts a Server serving data. The caller should call Close
+// when finished, to shut it down.
+func NewServer(data Dataset) *Server {
+	s := &Server{
+		data:       data,
+		keys:       map[string]bool{APIKey: true},
+		dailyLimit: 1000,
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL + "/v2/"
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns an airly.Client configured to use the server with APIKey.
+func (s *Server) Client() *airly.Client {
+	c, err := airly.NewClient(s.srv.Client(), APIKey)
+	if err != nil {
+		panic(err)
+	}
+	u, _ := url.Parse(s.URL)
+	return c.BaseURL(u)
+}
+
+// SetAPIKeys replaces the accepted api keys.
+func (s *Server) SetAPIKeys(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = map[string]bool{}
+	for _, k := range keys {
+		s.keys[k] = true
+	}
+}
+
+// SetDailyLimit sets the daily request quota and resets its usage.
+func (s *Server) SetDailyLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dailyLimit = limit
+	s.used = 0
+}
+
+// Put adds or replaces an installation and its measurements.
+func (s *Server) Put(inst airly.Installation, m airly.Measurement) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.data.Installations {
+		if s.data.Installations[i].ID == inst.ID {
+			s.data.Installations[i] = inst
+			s.data.Measurements[inst.ID] = m
+			return
+		}
+	}
+	s.data.Installations = append(s.data.Installations, inst)
+	if s.data.Measurements == nil {
+		s.data.Measurements = map[int64]airly.Measurement{}
+	}
+	s.data.Measurements[inst.ID] = m
+}
+
+// Fail injects f into responses of requests to path, relative to URL
+// (e.g. "measurements/installation"). An empty path matches all requests.
+func (s *Server) Fail(path string, f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.Status == 0 {
+		f.Status = http.StatusInternalServerError
+	}
+	s.failures = append(s.failures, &failure{path: path, Failure: f})
+}
+
+// ClearFailures removes all injected failures.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// Requests returns the number of requests received by the server.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	if path == r.URL.Path {
+		s.writeError(w, http.StatusNotFound, "NOT_FOUND", "Not found", nil)
+		return
+	}
+
+	if !s.keys[r.Header.Get("apiKey")] {
+		s.writeError(w, http.StatusUnauthorized, "INVALID_API_KEY", "Invalid authentication credentials", nil)
+		return
+	}
+
+	if s.dailyLimit > 0 {
+		if s.used >= s.dailyLimit {
+			w.Header().Set("X-RateLimit-Limit-day", strconv.Itoa(s.dailyLimit))
+			w.Header().Set("X-RateLimit-Remaining-day", "0")
+			s.writeError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "Rate limit exceeded", nil)
+			return
+		}
+		s.used++
+		w.Header().Set("X-RateLimit-Limit-day", strconv.Itoa(s.dailyLimit))
+		w.Header().Set("X-RateLimit-Remaining-day", strconv.Itoa(s.dailyLimit-s.used))
+	}
+
+	if f := s.failure(path); f != nil {
+		s.inject(w, f)
+		return
+	}
+
+	lang := language(r.Header.Get("Accept-Language"))
+	q := r.URL.Query()
+	switch {
+	case path == "installations/nearest":
+		s.nearestInstallations(w, q)
+	case strings.HasPrefix(path, "installations/"):
+		s.installation(w, strings.TrimPrefix(path, "installations/"))
+	case path == "measurements/installation":
+		s.measurementByID(w, q, lang)
+	case path == "measurements/nearest":
+		s.measurementNearest(w, q, lang)
+	case path == "measurements/point":
+		s.measurementPoint(w, q, lang)
+	case path == "meta/indexes":
+		s.writeJSON(w, s.localizeIndexes(lang))
+	case path == "meta/measurements":
+		s.writeJSON(w, s.localizeMeasurementTypes(lang))
+	default:
+		s.writeError(w, http.StatusNotFound, "NOT_FOUND", "Not found", nil)
+	}
+}
+
+func (s *Server) failure(path string) *failure {
+	for i, f := range s.failures {
+		if f.path != "" && f.path != path {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (s *Server) inject(w http.ResponseWriter, f *failure) {
+	if f.Delay > 0 {
+		s.mu.Unlock()
+		time.Sleep(f.Delay)
+		s.mu.Lock()
+	}
+	if f.CloseConnection {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+	if f.ErrorCode == "" && f.Message == "" {
+		w.WriteHeader(f.Status)
+		return
+	}
+	s.writeError(w, f.Status, f.ErrorCode, f.Message, nil)
+}
+
+func (s *Server) installation(w http.ResponseWriter, rawID string) {
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "BAD_REQUEST", "Invalid installation id", nil)
+		return
+	}
+	for _, inst := range s.data.Installations {
+		if inst.ID == id {
+			s.writeJSON(w, inst)
+			return
+		}
+	}
+	s.writeNotFound(w, id)
+}
+
+func (s *Server) nearestInstallations(w http.ResponseWriter, q url.Values) {
+	p := parser{q: q}
+	lat, lng := p.location()
+	maxKM := p.float("maxDistanceKM", defaultMaxDistanceKM)
+	maxResults := int(p.float("maxResults", defaultMaxResults))
+	if p.fail(s, w) {
+		return
+	}
+
+	found := s.nearest(lat, lng, maxKM)
+	if maxResults >= 0 && len(found) > maxResults {
+		found = found[:maxResults]
+	}
+	s.writeJSON(w, found)
+}
+
+func (s *Server) measurementByID(w http.ResponseWriter, q url.Values, lang string) {
+	p := parser{q: q}
+	id := int64(p.float("installationId", math.NaN()))
+	if p.fail(s, w) {
+		return
+	}
+
+	m, ok := s.data.Measurements[id]
+	if !ok {
+		s.writeNotFound(w, id)
+		return
+	}
+	s.writeMeasurement(w, m, q, lang)
+}
+
+func (s *Server) measurementNearest(w http.ResponseWriter, q url.Values, lang string) {
+	p := parser{q: q}
+	lat, lng := p.location()
+	maxKM := p.float("maxDistanceKM", defaultMaxDistanceKM)
+	if p.fail(s, w) {
+		return
+	}
+
+	s.writeNearestMeasurement(w, lat, lng, maxKM, q, lang)
+}
+
+func (s *Server) measurementPoint(w http.ResponseWriter, q url.Values, lang string) {
+	p := parser{q: q}
+	lat, lng := p.location()
+	if p.fail(s, w) {
+		return
+	}
+
+	// The Airly API interpolates nearby installations,
+	// the fake approximates it with the nearest one.
+	s.writeNearestMeasurement(w, lat, lng, pointMaxDistanceKM, q, lang)
+}
+
+func (s *Server) writeNearestMeasurement(w http.ResponseWriter, lat, lng, maxKM float64, q url.Values, lang string) {
+	for _, inst := range s.nearest(lat, lng, maxKM) {
+		if m, ok := s.data.Measurements[inst.ID]; ok {
+			s.writeMeasurement(w, m, q, lang)
+			return
+		}
+	}
+	s.writeError(w, http.StatusNotFound, "NOT_FOUND", "No installation found nearby", nil)
+}
+
+// nearest returns installations within maxKM from the location,
+// sorted by distance.
+func (s *Server) nearest(lat, lng, maxKM float64) []airly.Installation {
+	type found struct {
+		inst airly.Installation
+		km   float64
+	}
+	var all []found
+	for _, inst := range s.data.Installations {
+		km := Distance(lat, lng, inst.Location.Latitude, inst.Location.Longitude)
+		if km <= maxKM {
+			all = append(all, found{inst, km})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].km < all[j].km
+	})
+
+	installations := make([]airly.Installation, 0, len(all))
+	for _, f := range all {
+		installations = append(installations, f.inst)
+	}
+	return installations
+}
+
+func (s *Server) writeMeasurement(w http.ResponseWriter, m airly.Measurement, q url.Values, lang string) {
+	index := q.Get("indexType")
+	if index == "" {
+		index = defaultIndexType
+	}
+	wind := q.Get("includeWind") == "true"
+
+	data := func(d airly.Data) airly.Data {
+		out := airly.Data{
+			FromDateTime: d.FromDateTime,
+			TillDateTime: d.TillDateTime,
+			Values:       []airly.Value{},
+			Indexes:      []airly.Index{},
+			Standards:    d.Standards,
+		}
+		if out.Standards == nil {
+			out.Standards = []airly.Standard{}
+		}
+		for _, v := range d.Values {
+			if !wind && strings.HasPrefix(v.Name, "WIND_") {
+				continue
+			}
+			out.Values = append(out.Values, v)
+		}
+		for _, idx := range d.Indexes {
+			if idx.Name != index {
+				continue
+			}
+			idx.Description = s.translate(lang, idx.Description)
+			idx.Advice = s.translate(lang, idx.Advice)
+			out.Indexes = append(out.Indexes, idx)
+		}
+		return out
+	}
+
+	out := airly.Measurement{
+		Current:  data(m.Current),
+		History:  make([]airly.Data, 0, len(m.History)),
+		Forecast: make([]airly.Data, 0, len(m.Forecast)),
+	}
+	for _, d := range m.History {
+		out.History = append(out.History, data(d))
+	}
+	for _, d := range m.Forecast {
+		out.Forecast = append(out.Forecast, data(d))
+	}
+	s.writeJSON(w, out)
+}
+
+func (s *Server) localizeIndexes(lang string) []airly.IndexType {
+	out := make([]airly.IndexType, 0, len(s.data.Indexes))
+	for _, it := range s.data.Indexes {
+		levels := make([]airly.Level, 0, len(it.Levels))
+		for _, l := range it.Levels {
+			l.Description = s.translate(lang, l.Description)
+			levels = append(levels, l)
+		}
+		out = append(out, airly.IndexType{Name: it.Name, Levels: levels})
+	}
+	return out
+}
+
+func (s *Server) localizeMeasurementTypes(lang string) []airly.MeasurementType {
+	out := make([]airly.MeasurementType, 0, len(s.data.MeasurementTypes))
+	for _, mt := range s.data.MeasurementTypes {
+		mt.Label = s.translate(lang, mt.Label)
+		out = append(out, mt)
+	}
+	return out
+}
+
+func (s *Server) translate(lang, text string) string {
+	if t, ok := s.data.Translations[lang][text]; ok {
+		return t
+	}
+	return text
+}
+
+// language returns the primary language of an Accept-Language header.
+func language(header string) string {
+	lang := strings.TrimSpace(strings.Split(header, ",")[0])
+	lang = strings.Split(lang, ";")[0]
+	lang = strings.Split(lang, "-")[0]
+	return strings.ToLower(lang)
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		panic(fmt.Sprintf("airlytest: encode response: %v", err))
+	}
+}
+
+func (s *Server) writeNotFound(w http.ResponseWriter, id int64) {
+	s.writeError(w, http.StatusNotFound, "INSTALLATION_NOT_FOUND",
+		fmt.Sprintf("Installation with id %d not found", id), nil)
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, code, msg string, violations []airly.Violation) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	e := airly.Error{
+		ErrorCode: code,
+		Message:   msg,
+		Details:   airly.Details{Violations: violations},
+	}
+	if err := json.NewEncoder(w).Encode(e); err != nil {
+		panic(fmt.Sprintf("airlytest: encode error: %v", err))
+	}
+}
+
+// parser reads numeric query params, collecting violations like the Airly API.
+type parser struct {
+	q          url.Values
+	violations []airly.Violation
+}
+
+func (p *parser) float(name string, def float64) float64 {
+	raw := p.q.Get(name)
+	if raw == "" {
+		if math.IsNaN(def) {
+			p.violations = append(p.violations, airly.Violation{Parameter: name, Message: "must not be null"})
+		}
+		return def
+	}
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		p.violations = append(p.violations, airly.Violation{Parameter: name, Message: "must be a number"})
+	}
+	return f
+}
+
+func (p *parser) location() (lat, lng float64) {
+	lat = p.float("lat", math.NaN())
+	lng = p.float("lng", math.NaN())
+	if lat < -90 || lat > 90 {
+		p.violations = append(p.violations, airly.Violation{Parameter: "lat", Message: "must be between -90 and 90", RejectedValue: int64(lat)})
+	}
+	if lng < -180 || lng > 180 {
+		p.violations = append(p.violations, airly.Violation{Parameter: "lng", Message: "must be between -180 and 180", RejectedValue: int64(lng)})
+	}
+	return lat, lng
+}
+
+// fail writes a bad request response if any violation was found.
+func (p *parser) fail(s *Server, w http.ResponseWriter) bool {
+	if len(p.violations) == 0 {
+		return false
+	}
+	s.writeError(w, http.StatusBadRequest, "BAD_REQUEST", "Bad request", p.violations)
+	return true
+}
+
+// Distance returns the great-circle distance in kilometers between
+// two points given in degrees.
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadiusKM = 6371
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKM * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,176 @@
+package airlytest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/lsjurczak/go-airly"
+)
+
+func TestServer_installations(t *testing.T) {
+	s := NewServer(DefaultDataset())
+	defer s.Close()
+	client := s.Client()
+
+	inst, err := client.Installation.ByID(context.Background(), 8077)
+	if err != nil {
+		t.Fatalf("Installation.ByID: %v", err)
+	}
+	if inst.Address.Street != "Mikołajska" {
+		t.Errorf("Installation.ByID returned %+v", inst)
+	}
+
+	opt := airly.NewNearestInstallationOpts(50.062, 19.94).MaxResults(airly.UnlimitedResults)
+	got, err := client.Installation.Nearest(context.Background(), opt)
+	if err != nil {
+		t.Fatalf("Installation.Nearest: %v", err)
+	}
+	var ids []int64
+	for _, inst := range got {
+		ids = append(ids, inst.ID)
+	}
+	if want := []int64{8077, 2139, 8078}; !equalIDs(ids, want) {
+		t.Errorf("Installation.Nearest returned %v, want %v", ids, want)
+	}
+
+	got, err = client.Installation.Nearest(context.Background(), opt.Clone().MaxResults(1).MaxDistance(0.1))
+	if err != nil {
+		t.Fatalf("Installation.Nearest: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 8077 {
+		t.Errorf("Installation.Nearest returned %+v, want only 8077", got)
+	}
+
+	_, err = client.Installation.ByID(context.Background(), 1)
+	var aerr airly.Error
+	if !errors.As(err, &aerr) || aerr.ErrorCode != "INSTALLATION_NOT_FOUND" {
+		t.Errorf("Installation.ByID(1) returned %v, want INSTALLATION_NOT_FOUND", err)
+	}
+}
+
+func TestServer_measurements(t *testing.T) {
+	s := NewServer(DefaultDataset())
+	defer s.Close()
+	client := s.Client()
+
+	m, err := client.Measurement.ByID(context.Background(), airly.NewByIDMeasurementOpts(9599).IndexType(airly.CAQI))
+	if err != nil {
+		t.Fatalf("Measurement.ByID: %v", err)
+	}
+	if len(m.History) != 23 || len(m.Forecast) != 24 {
+		t.Errorf("got %d history and %d forecast entries, want 23 and 24", len(m.History), len(m.Forecast))
+	}
+	if !m.Current.TillDateTime.Equal(Epoch) {
+		t.Errorf("current till %v, want %v", m.Current.TillDateTime, Epoch)
+	}
+	if len(m.Current.Indexes) != 1 || m.Current.Indexes[0].Name != "CAQI" {
+		t.Errorf("current indexes %+v, want only CAQI", m.Current.Indexes)
+	}
+	for _, v := range m.Current.Values {
+		if v.Name == "WIND_SPEED" {
+			t.Error("wind returned without includeWind")
+		}
+	}
+
+	m, err = client.Measurement.Nearest(context.Background(), airly.NewNearestMeasurementOpts(52.28, 21.1))
+	if err != nil {
+		t.Fatalf("Measurement.Nearest: %v", err)
+	}
+	want := DefaultDataset().Measurements[9599].Current.Values[1]
+	if got := m.Current.Values[1]; got != want {
+		t.Errorf("Measurement.Nearest returned %+v, want %+v", got, want)
+	}
+
+	_, err = client.Measurement.ForPoint(context.Background(), airly.NewForPointMeasurementOpts(0, 0))
+	var aerr airly.Error
+	if !errors.As(err, &aerr) || aerr.ErrorCode != "NOT_FOUND" {
+		t.Errorf("Measurement.ForPoint(0, 0) returned %v, want NOT_FOUND", err)
+	}
+}
+
+func TestServer_language(t *testing.T) {
+	s := NewServer(DefaultDataset())
+	defer s.Close()
+	client := s.Client().Language("pl")
+
+	types, err := client.Meta.Measurements(context.Background())
+	if err != nil {
+		t.Fatalf("Meta.Measurements: %v", err)
+	}
+	if got := types[3].Label; got != "Temperatura" {
+		t.Errorf("label %q, want Temperatura", got)
+	}
+
+	indexes, err := client.Meta.Indexes(context.Background())
+	if err != nil {
+		t.Fatalf("Meta.Indexes: %v", err)
+	}
+	if got := indexes[0].Levels[0].Description; got != "Świetne powietrze!" {
+		t.Errorf("description %q, want Świetne powietrze!", got)
+	}
+}
+
+func TestServer_apiKeyAndQuota(t *testing.T) {
+	s := NewServer(DefaultDataset())
+	defer s.Close()
+	client := s.Client()
+
+	s.SetDailyLimit(1)
+	if _, err := client.Meta.Indexes(context.Background()); err != nil {
+		t.Fatalf("Meta.Indexes: %v", err)
+	}
+	_, err := client.Meta.Indexes(context.Background())
+	var aerr airly.Error
+	if !errors.As(err, &aerr) || aerr.ErrorCode != "TOO_MANY_REQUESTS" {
+		t.Errorf("Meta.Indexes over quota returned %v, want TOO_MANY_REQUESTS", err)
+	}
+
+	s.SetAPIKeys("other")
+	_, err = client.Meta.Indexes(context.Background())
+	if !errors.As(err, &aerr) || aerr.ErrorCode != "INVALID_API_KEY" {
+		t.Errorf("Meta.Indexes with wrong key returned %v, want INVALID_API_KEY", err)
+	}
+	if got := s.Requests(); got != 3 {
+		t.Errorf("server received %d requests, want 3", got)
+	}
+}
+
+func TestServer_Fail(t *testing.T) {
+	s := NewServer(DefaultDataset())
+	defer s.Close()
+	client := s.Client()
+
+	s.Fail("meta/indexes", Failure{Status: http.StatusServiceUnavailable, ErrorCode: "UNAVAILABLE", Message: "Try later", Times: 1})
+	_, err := client.Meta.Indexes(context.Background())
+	var aerr airly.Error
+	if !errors.As(err, &aerr) || aerr.ErrorCode != "UNAVAILABLE" {
+		t.Errorf("Meta.Indexes returned %v, want injected failure", err)
+	}
+	if _, err := client.Meta.Indexes(context.Background()); err != nil {
+		t.Errorf("Meta.Indexes after failure: %v", err)
+	}
+
+	// Meta responses fall back to the embedded snapshot on network errors.
+	s.Fail("", Failure{CloseConnection: true})
+	if _, err := client.Installation.ByID(context.Background(), 9599); err == nil {
+		t.Error("Installation.ByID succeeded on closed connection")
+	}
+	s.ClearFailures()
+	if _, err := client.Installation.ByID(context.Background(), 9599); err != nil {
+		t.Errorf("Installation.ByID after ClearFailures: %v", err)
+	}
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
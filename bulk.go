@@ -0,0 +1,235 @@
+package airly
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultBulkWorkers = 4
+
+// bulkRateLimitCooldown is how long no requests are started after
+// a response reports that the per minute limit is used up.
+var bulkRateLimitCooldown = rateLimitCooldown
+
+// BulkOptions configures fetching measurements of many installations.
+type BulkOptions struct {
+	// Workers is the number of concurrent requests, 4 if zero.
+	Workers int
+	// Interval is the minimum time between the starts of two requests,
+	// e.g. to stay under a per-minute rate limit.
+	Interval time.Duration
+	// IndexType and IncludeWind are set on every query when not empty.
+	IndexType   IndexKind
+	IncludeWind bool
+}
+
+// BulkResult holds the measurement of a single installation or the error
+// that prevented fetching it.
+type BulkResult struct {
+	ID          int64
+	Measurement Measurement
+	Err         error
+}
+
+// BulkByID fetches measurements of all installations given by ids and
+// returns the results by installation ID. See StreamByID, IDs not fetched
+// before ctx is done may be missing.
+func BulkByID(ctx context.Context, api MeasurementAPI, ids []int64, opts BulkOptions) map[int64]BulkResult {
+	results := make(map[int64]BulkResult, len(ids))
+	for r := range StreamByID(ctx, api, ids, opts) {
+		results[r.ID] = r
+	}
+	return results
+}
+
+// StreamByID fetches measurements of all installations given by ids
+// using a pool of workers and sends the results as they arrive.
+// Every unique ID gets exactly one result, then the channel is closed.
+//
+// When api returns response metadata, like MeasurementService, and
+// a response reports that the per minute rate limit is used up, no
+// requests are started for a minute. When the API responds with 429 Too
+// Many Requests, no further requests are sent and the remaining IDs get
+// that error. Daily limits are not tracked, the API rejects requests over
+// them with 429 too.
+//
+// When ctx is done, no further requests are sent. The remaining IDs get
+// the context error while the channel is read, but results are dropped
+// instead of blocking, so a caller can cancel ctx and stop reading
+// without leaking the workers.
+func StreamByID(ctx context.Context, api MeasurementAPI, ids []int64, opts BulkOptions) <-chan BulkResult {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBulkWorkers
+	}
+
+	out := make(chan BulkResult)
+	jobs := make(chan int64)
+	b := &bulk{api: api, opts: opts}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				if !send(ctx, out, b.fetch(ctx, id)) {
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+
+		var tick <-chan time.Time
+		if opts.Interval > 0 {
+			ticker := time.NewTicker(opts.Interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for i, id := range unique(ids) {
+			if i > 0 && tick != nil {
+				select {
+				case <-tick:
+				case <-ctx.Done():
+				}
+			}
+			if err := b.stopped(ctx); err != nil {
+				if !send(ctx, out, BulkResult{ID: id, Err: err}) {
+					return
+				}
+				continue
+			}
+			select {
+			case jobs <- id:
+			case <-ctx.Done():
+				if !send(ctx, out, BulkResult{ID: id, Err: ctx.Err()}) {
+					return
+				}
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// send sends r unless ctx is done and nobody is receiving. It reports
+// whether r was sent.
+func send(ctx context.Context, out chan<- BulkResult, r BulkResult) bool {
+	select {
+	case out <- r:
+		return true
+	case <-ctx.Done():
+	}
+	select {
+	case out <- r:
+		return true
+	default:
+		return false
+	}
+}
+
+// byIDWithResponse is implemented by MeasurementAPIs that return response
+// metadata, which includes the rate limits.
+type byIDWithResponse interface {
+	ByIDWithResponse(ctx context.Context, opts *ByIDMeasurementOpts) (Measurement, *Response, error)
+}
+
+type bulk struct {
+	api  MeasurementAPI
+	opts BulkOptions
+
+	mu          sync.Mutex
+	rateLimited error
+	// cooldown is the time no requests are started before.
+	cooldown time.Time
+}
+
+func (b *bulk) fetch(ctx context.Context, id int64) BulkResult {
+	b.waitCooldown(ctx)
+	if err := b.stopped(ctx); err != nil {
+		return BulkResult{ID: id, Err: err}
+	}
+
+	q := NewByIDMeasurementOpts(id)
+	if b.opts.IndexType != "" {
+		q.IndexType(b.opts.IndexType)
+	}
+	if b.opts.IncludeWind {
+		q.IncludeWind(true)
+	}
+
+	var (
+		m    Measurement
+		resp *Response
+		err  error
+	)
+	if api, ok := b.api.(byIDWithResponse); ok {
+		m, resp, err = api.ByIDWithResponse(ctx, q)
+	} else {
+		m, err = b.api.ByID(ctx, q)
+	}
+
+	b.mu.Lock()
+	var e Error
+	if errors.As(err, &e) && e.StatusCode == http.StatusTooManyRequests {
+		b.rateLimited = err
+	}
+	if resp != nil && resp.RateLimits.Minute.Remaining == 0 {
+		b.cooldown = time.Now().Add(bulkRateLimitCooldown)
+	}
+	b.mu.Unlock()
+	return BulkResult{ID: id, Measurement: m, Err: err}
+}
+
+// waitCooldown waits until the per minute rate limit is available again
+// or ctx is done.
+func (b *bulk) waitCooldown(ctx context.Context) {
+	b.mu.Lock()
+	d := time.Until(b.cooldown)
+	b.mu.Unlock()
+	if d <= 0 {
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// stopped returns the error that prevents sending further requests.
+func (b *bulk) stopped(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rateLimited
+}
+
+func unique(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
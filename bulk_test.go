@@ -0,0 +1,158 @@
+package airly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBulkByID(t *testing.T) {
+	var running, maxRunning int32
+	notFound := Error{ErrorCode: "INSTALLATION_NOT_FOUND", StatusCode: http.StatusNotFound}
+	fake := &FakeMeasurementService{
+		ByIDFunc: func(ctx context.Context, opts *ByIDMeasurementOpts) (Measurement, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+					break
+				}
+			}
+			if opts.opts.Get("installationId") == "3" {
+				return Measurement{}, notFound
+			}
+			if opts.opts.Get("indexType") != string(PIJP) {
+				t.Errorf("query %v without index type", opts.opts)
+			}
+			return mockMeasurement, nil
+		},
+	}
+
+	ids := []int64{1, 2, 3, 4, 5, 6, 7, 8, 2}
+	got := BulkByID(context.Background(), fake, ids, BulkOptions{Workers: 2, IndexType: PIJP})
+
+	if len(got) != 8 {
+		t.Fatalf("got %d results, want 8", len(got))
+	}
+	for id, r := range got {
+		if id == 3 {
+			if !hasErrorCode(r.Err, notFound.ErrorCode) {
+				t.Errorf("result of 3: %v, want not found", r.Err)
+			}
+			continue
+		}
+		if r.Err != nil || r.ID != id || r.Measurement.Current.TillDateTime.IsZero() {
+			t.Errorf("result of %d: %+v", id, r)
+		}
+	}
+	if n := len(fake.Calls()); n != 8 {
+		t.Errorf("made %d calls, want 8", n)
+	}
+	if max := atomic.LoadInt32(&maxRunning); max > 2 {
+		t.Errorf("%d concurrent calls, want at most 2", max)
+	}
+}
+
+func TestStreamByID_rateLimited(t *testing.T) {
+	tooMany := Error{ErrorCode: "TOO_MANY_REQUESTS", StatusCode: http.StatusTooManyRequests}
+	fake := &FakeMeasurementService{
+		ByIDFunc: func(ctx context.Context, opts *ByIDMeasurementOpts) (Measurement, error) {
+			if opts.opts.Get("installationId") == "2" {
+				return Measurement{}, tooMany
+			}
+			return mockMeasurement, nil
+		},
+	}
+
+	var results, limited int
+	for r := range StreamByID(context.Background(), fake, []int64{1, 2, 3, 4, 5}, BulkOptions{Workers: 1}) {
+		results++
+		if hasErrorCode(r.Err, tooMany.ErrorCode) {
+			limited++
+		}
+	}
+	if results != 5 {
+		t.Errorf("got %d results, want 5", results)
+	}
+	if limited != 4 {
+		t.Errorf("%d results rate limited, want 4", limited)
+	}
+	if n := len(fake.Calls()); n != 2 {
+		t.Errorf("made %d calls after rate limit, want 2", n)
+	}
+}
+
+func TestStreamByID_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fake := &FakeMeasurementService{}
+	for r := range StreamByID(ctx, fake, []int64{1, 2, 3}, BulkOptions{}) {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("result of %d: %v, want context.Canceled", r.ID, r.Err)
+		}
+	}
+	if n := len(fake.Calls()); n != 0 {
+		t.Errorf("made %d calls with canceled context, want 0", n)
+	}
+}
+
+func TestStreamByID_canceledWithoutReading(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	ids := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	results := StreamByID(ctx, &FakeMeasurementService{}, ids, BulkOptions{Workers: 3})
+	<-results
+	cancel()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines still running after cancel, want %d", runtime.NumGoroutine(), before)
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestStreamByID_minuteRateLimit(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	defer func(d time.Duration) { bulkRateLimitCooldown = d }(bulkRateLimitCooldown)
+	bulkRateLimitCooldown = 100 * time.Millisecond
+
+	var starts []time.Time
+	mux.HandleFunc("/measurements/installation", func(w http.ResponseWriter, r *http.Request) {
+		starts = append(starts, time.Now())
+		if len(starts) == 1 {
+			w.Header().Set("X-RateLimit-Remaining-minute", "0")
+		}
+		fmt.Fprint(w, mockMeasurementResponse)
+	})
+
+	got := BulkByID(context.Background(), client.Measurement, []int64{1, 2, 3}, BulkOptions{Workers: 1})
+	for id, r := range got {
+		if r.Err != nil {
+			t.Errorf("result of %d: %v", id, r.Err)
+		}
+	}
+	if len(starts) != 3 {
+		t.Fatalf("sent %d requests, want 3", len(starts))
+	}
+	if d := starts[1].Sub(starts[0]); d < bulkRateLimitCooldown {
+		t.Errorf("second request sent %v after the limit was used up, want at least %v", d, bulkRateLimitCooldown)
+	}
+	if d := starts[2].Sub(starts[1]); d >= bulkRateLimitCooldown {
+		t.Errorf("third request waited %v without rate limit", d)
+	}
+}
+
+func hasErrorCode(err error, code string) bool {
+	var e Error
+	return errors.As(err, &e) && e.ErrorCode == code
+}
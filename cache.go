@@ -0,0 +1,320 @@
+package airly
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	// measurementInterval is how often Airly publishes new measurements.
+	measurementInterval = time.Hour
+	// minMeasurementTTL keeps measurements with outdated TillDateTime
+	// from being requested on every call.
+	minMeasurementTTL = time.Minute
+	// defaultLongTTL applies to meta data and installations by default.
+	defaultLongTTL = 24 * time.Hour
+)
+
+// CacheEntry is a cached API response.
+type CacheEntry struct {
+	Body    []byte      `json:"body"`
+	Header  http.Header `json:"header,omitempty"`
+	Stored  time.Time   `json:"stored"`
+	Expires time.Time   `json:"expires"`
+}
+
+// Cache stores API responses by a key derived from the request.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+	Delete(key string)
+}
+
+// CacheOptions configures how a Client uses its Cache.
+//
+// Measurements stay fresh until new data is due, which is an hour after
+// Current.TillDateTime.
+type CacheOptions struct {
+	// MetaTTL is how long MetaService responses stay fresh, 24h if zero.
+	MetaTTL time.Duration
+	// InstallationTTL is how long InstallationService.ByID responses
+	// stay fresh, 24h if zero.
+	InstallationTTL time.Duration
+	// StaleWhileRevalidate is how long after expiry an entry is still
+	// returned while it is refreshed in the background.
+	StaleWhileRevalidate time.Duration
+	// StaleIfError is how long after expiry an entry is still returned
+	// when refreshing it fails with a network error, a server error
+	// or exceeded rate limit.
+	StaleIfError time.Duration
+}
+
+// Cache enables caching of API responses in cache. Pass a nil cache
+// to disable caching.
+func (c *Client) Cache(cache Cache, opts CacheOptions) *Client {
+	if opts.MetaTTL == 0 {
+		opts.MetaTTL = defaultLongTTL
+	}
+	if opts.InstallationTTL == 0 {
+		opts.InstallationTTL = defaultLongTTL
+	}
+	c.cache = cache
+	c.cacheOpts = opts
+	return c
+}
+
+func cacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String() + " " + req.Header.Get("Accept-Language")
+}
+
+func (c *Client) cachedGet(req *http.Request, result interface{}) (*Response, error) {
+	key := cacheKey(req)
+	now := c.now()
+
+	entry, ok := c.cache.Get(key)
+	if ok && now.Before(entry.Expires) {
+		return entry.response(), decode(entry.Body, result)
+	}
+	if ok && now.Before(entry.Expires.Add(c.cacheOpts.StaleWhileRevalidate)) {
+		c.revalidate(req, key, result)
+		return entry.response(), decode(entry.Body, result)
+	}
+
+	resp, err := c.fetch(req, key, result)
+	if err != nil && ok && staleOnError(err) && now.Before(entry.Expires.Add(c.cacheOpts.StaleIfError)) {
+		return entry.response(), decode(entry.Body, result)
+	}
+	return resp, err
+}
+
+func (e CacheEntry) response() *Response {
+	r := responseFromHeader(http.StatusOK, e.Header, e.Body)
+	r.Cached = true
+	return r
+}
+
+// fetch requests and decodes the result, storing the response in cache.
+func (c *Client) fetch(req *http.Request, key string, result interface{}) (*Response, error) {
+	body, resp, err := c.do(req)
+	if err != nil {
+		return resp, err
+	}
+	if err := decode(body, result); err != nil {
+		return resp, err
+	}
+
+	now := c.now()
+	if ttl := c.cacheTTL(result, now); ttl > 0 {
+		c.cache.Set(key, CacheEntry{Body: body, Header: resp.Header, Stored: now, Expires: now.Add(ttl)})
+	}
+	return resp, nil
+}
+
+// revalidate refreshes the entry under key in the background,
+// unless it is already being refreshed.
+func (c *Client) revalidate(req *http.Request, key string, result interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.revalidating[key] {
+		return
+	}
+	if c.revalidating == nil {
+		c.revalidating = map[string]bool{}
+	}
+	c.revalidating[key] = true
+
+	// The refresh outlives the caller, so it must not use its context.
+	req = req.WithContext(context.Background())
+	fresh := reflect.New(reflect.TypeOf(result).Elem()).Interface()
+	go func() {
+		// Errors are ignored, the next call tries again.
+		_, _ = c.fetch(req, key, fresh)
+
+		c.mu.Lock()
+		delete(c.revalidating, key)
+		c.mu.Unlock()
+	}()
+}
+
+func (c *Client) cacheTTL(result interface{}, now time.Time) time.Duration {
+	switch r := result.(type) {
+	case *Measurement:
+		ttl := r.Current.TillDateTime.Add(measurementInterval).Sub(now)
+		if ttl < minMeasurementTTL {
+			ttl = minMeasurementTTL
+		}
+		return ttl
+	case *Installation:
+		return c.cacheOpts.InstallationTTL
+	case *[]IndexType, *[]MeasurementType:
+		return c.cacheOpts.MetaTTL
+	}
+	return 0
+}
+
+// staleOnError reports whether a stale entry may replace err.
+func staleOnError(err error) bool {
+	var e Error
+	if errors.As(err, &e) {
+		return e.StatusCode >= http.StatusInternalServerError ||
+			e.StatusCode == http.StatusTooManyRequests
+	}
+	var verr ValidationError
+	return !errors.As(err, &verr)
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// entries when full.
+type MemoryCache struct {
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type memoryItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCache creates a MemoryCache holding up to maxEntries entries,
+// each for at most ttl after it was stored. Zero values mean no limit.
+// Keeping entries past their expiry allows them to be served stale.
+func NewMemoryCache(maxEntries int, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		now:        time.Now,
+		ll:         list.New(),
+		items:      map[string]*list.Element{},
+	}
+}
+
+// Get returns the entry stored under key.
+func (m *MemoryCache) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.items[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	item := el.Value.(*memoryItem)
+	if m.ttl > 0 && m.now().Sub(item.entry.Stored) > m.ttl {
+		m.remove(el)
+		return CacheEntry{}, false
+	}
+	m.ll.MoveToFront(el)
+	return item.entry, true
+}
+
+// Set stores entry under key, evicting the least recently used entry
+// if the cache is full.
+func (m *MemoryCache) Set(key string, entry CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		el.Value.(*memoryItem).entry = entry
+		m.ll.MoveToFront(el)
+		return
+	}
+	m.items[key] = m.ll.PushFront(&memoryItem{key: key, entry: entry})
+	if m.maxEntries > 0 && m.ll.Len() > m.maxEntries {
+		m.remove(m.ll.Back())
+	}
+}
+
+// Delete removes the entry stored under key.
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+func (m *MemoryCache) remove(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.items, el.Value.(*memoryItem).key)
+}
+
+// DiskCache is a Cache storing entries as files in a directory,
+// so that they survive restarts. Entries that cannot be read or written
+// are treated as missing.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates a DiskCache in dir, creating the directory if needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the entry stored under key.
+func (d *DiskCache) Get(key string) (CacheEntry, bool) {
+	b, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Set stores entry under key. The file is replaced atomically,
+// so concurrent readers never see a partial entry.
+func (d *DiskCache) Set(key string, entry CacheEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	f, err := os.CreateTemp(d.dir, "entry-*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), d.path(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// Delete removes the entry stored under key.
+func (d *DiskCache) Delete(key string) {
+	os.Remove(d.path(key))
+}
@@ -0,0 +1,179 @@
+package airly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Cache_measurement(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	var requests int32
+	mux.HandleFunc("/measurements/installation", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, mockMeasurementResponse)
+	})
+
+	// Current data is till 15:00, new data is due at 16:00.
+	now := time.Date(2020, 5, 7, 15, 10, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+	client.Cache(NewMemoryCache(10, 0), CacheOptions{})
+
+	opt := NewByIDMeasurementOpts(6600)
+	for i := 0; i < 2; i++ {
+		got, err := client.Measurement.ByID(context.Background(), opt)
+		if err != nil {
+			t.Fatalf("Measurement.ByID: %v", err)
+		}
+		if !reflect.DeepEqual(got, mockMeasurement) {
+			t.Errorf("Measurement.ByID returned %+v, want %+v", got, mockMeasurement)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("sent %d requests before expiry, want 1", got)
+	}
+
+	now = time.Date(2020, 5, 7, 16, 0, 0, 0, time.UTC)
+	if _, err := client.Measurement.ByID(context.Background(), opt); err != nil {
+		t.Fatalf("Measurement.ByID: %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("sent %d requests after expiry, want 2", got)
+	}
+}
+
+func TestClient_Cache_staleIfError(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	fail := false
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"errorCode":"UNAVAILABLE","message":"Try later"}`)
+			return
+		}
+		fmt.Fprint(w, mockIndexesResponse)
+	})
+
+	now := time.Date(2020, 5, 7, 15, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+	client.Cache(NewMemoryCache(10, 0), CacheOptions{MetaTTL: time.Hour, StaleIfError: time.Hour})
+
+	if _, err := client.Meta.Indexes(context.Background()); err != nil {
+		t.Fatalf("Meta.Indexes: %v", err)
+	}
+
+	fail = true
+	now = now.Add(90 * time.Minute)
+	got, err := client.Meta.Indexes(context.Background())
+	if err != nil {
+		t.Fatalf("Meta.Indexes with stale entry: %v", err)
+	}
+	if !reflect.DeepEqual(got, mockIndexes) {
+		t.Errorf("Meta.Indexes returned %+v, want %+v", got, mockIndexes)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := client.Meta.Indexes(context.Background()); err == nil {
+		t.Error("Meta.Indexes returned entry older than StaleIfError")
+	}
+}
+
+func TestClient_Cache_staleWhileRevalidate(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	refreshed := make(chan struct{}, 2)
+	mux.HandleFunc("/installations/6600", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, mockInstallationResponse)
+		refreshed <- struct{}{}
+	})
+
+	now := time.Date(2020, 5, 7, 15, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+	cache := NewMemoryCache(10, 0)
+	client.Cache(cache, CacheOptions{InstallationTTL: time.Hour, StaleWhileRevalidate: time.Hour})
+
+	if _, err := client.Installation.ByID(context.Background(), 6600); err != nil {
+		t.Fatalf("Installation.ByID: %v", err)
+	}
+	<-refreshed
+
+	now = now.Add(90 * time.Minute)
+	got, err := client.Installation.ByID(context.Background(), 6600)
+	if err != nil {
+		t.Fatalf("Installation.ByID with stale entry: %v", err)
+	}
+	if !reflect.DeepEqual(got, mockInstallation) {
+		t.Errorf("Installation.ByID returned %+v, want %+v", got, mockInstallation)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("stale entry was not revalidated")
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache(2, time.Hour)
+	now := time.Date(2020, 5, 7, 15, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", CacheEntry{Body: []byte("a"), Stored: now})
+	c.Set("b", CacheEntry{Body: []byte("b"), Stored: now})
+	c.Get("a")
+	c.Set("c", CacheEntry{Body: []byte("c"), Stored: now})
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("recently used entry was evicted")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := c.Get("c"); ok {
+		t.Error("entry older than ttl was returned")
+	}
+	if got := c.Len(); got != 1 {
+		t.Errorf("Len = %d, want 1", got)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir, err := os.MkdirTemp("", "airly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache: %v", err)
+	}
+	want := CacheEntry{
+		Body:    []byte(`{"id":1}`),
+		Stored:  time.Date(2020, 5, 7, 15, 0, 0, 0, time.UTC),
+		Expires: time.Date(2020, 5, 7, 16, 0, 0, 0, time.UTC),
+	}
+	c.Set("key", want)
+
+	got, ok := c.Get("key")
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("Get returned %+v, %v, want %+v", got, ok, want)
+	}
+
+	c.Delete("key")
+	if _, ok := c.Get("key"); ok {
+		t.Error("deleted entry was returned")
+	}
+}
@@ -0,0 +1,59 @@
+// Command airly-meta-snapshot fetches the indexes and measurement types
+// from the Airly API in every language and writes them as a snapshot that
+// the airly package embeds.
+//
+// Usage, from the repository root:
+//
+//	AIRLY_API_KEY=... go generate
+//
+// or
+//
+//	AIRLY_API_KEY=... airly-meta-snapshot -o metadata/snapshot.json -languages en,pl
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lsjurczak/go-airly"
+)
+
+func main() {
+	out := flag.String("o", "metadata/snapshot.json", "output file")
+	languages := flag.String("languages", "en,pl", "comma separated languages")
+	flag.Parse()
+
+	apiKey := os.Getenv("AIRLY_API_KEY")
+	if apiKey == "" {
+		log.Fatal("AIRLY_API_KEY is not set")
+	}
+	client, err := airly.NewClient(nil, apiKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var langs []airly.Language
+	for _, s := range strings.Split(*languages, ",") {
+		lang, err := airly.ParseLanguage(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		langs = append(langs, lang)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	version := time.Now().UTC().Format("2006-01-02")
+	snapshot, err := client.RefreshMetaSnapshot(ctx, version, langs...)
+	if err != nil {
+		log.Fatalf("refresh snapshot: %v", err)
+	}
+	if err := snapshot.WriteFile(*out); err != nil {
+		log.Fatalf("write snapshot: %v", err)
+	}
+	log.Printf("wrote snapshot %s to %s", version, *out)
+}
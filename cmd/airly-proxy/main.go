@@ -0,0 +1,69 @@
+// Command airly-proxy is a caching proxy in front of the Airly API that lets
+// several services share one API key.
+//
+// It serves the same /v2/ paths as the Airly API. Clients authenticate with
+// their own tokens sent in the apiKey header, so an airly.Client only needs
+// its base URL pointed at the proxy. The real key is read from the
+// AIRLY_API_KEY environment variable and never leaves the proxy.
+//
+// Clients are listed in a JSON file:
+//
+//	[{"name": "web", "token": "secret", "dailyQuota": 500}]
+//
+// Usage:
+//
+//	AIRLY_API_KEY=... airly-proxy -clients clients.json -addr :8080
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lsjurczak/go-airly"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	upstream := flag.String("upstream", "https://airapi.airly.eu/v2/", "Airly API base URL")
+	clientsPath := flag.String("clients", "clients.json", "JSON file with client tokens and quotas")
+	cacheSize := flag.Int("cache-size", 10000, "maximum number of cached responses")
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "", log.LstdFlags|log.LUTC)
+
+	apiKey := os.Getenv("AIRLY_API_KEY")
+	if apiKey == "" {
+		logger.Fatal("AIRLY_API_KEY is not set")
+	}
+	u, err := url.Parse(*upstream)
+	if err != nil {
+		logger.Fatalf("parse upstream: %v", err)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	clients, err := loadClients(*clientsPath)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	p := newProxy(u, apiKey, clients,
+		&http.Client{Timeout: 10 * time.Second},
+		airly.NewMemoryCache(*cacheSize, time.Hour),
+		logger,
+	)
+
+	srv := &http.Server{
+		Addr:         *addr,
+		Handler:      p,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	logger.Printf("listening on %s", *addr)
+	logger.Fatal(srv.ListenAndServe())
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	pathpkg "path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lsjurczak/go-airly"
+)
+
+// Client is a consumer of the proxy, authenticated by its own token
+// sent in the apiKey header, like the Airly API key.
+type Client struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+	// DailyQuota limits requests per UTC day, zero means no limit.
+	DailyQuota int `json:"dailyQuota"`
+}
+
+type usage struct {
+	day  time.Time
+	used int
+}
+
+// proxy forwards requests to the Airly API with the real api key
+// and caches successful responses until the end of the hour.
+type proxy struct {
+	upstream *url.URL
+	apiKey   string
+	doer     airly.HTTPDoer
+	cache    airly.Cache
+	log      *log.Logger
+	now      func() time.Time
+
+	clients map[string]Client
+
+	mu    sync.Mutex
+	usage map[string]*usage
+}
+
+func newProxy(upstream *url.URL, apiKey string, clients []Client, doer airly.HTTPDoer, cache airly.Cache, logger *log.Logger) *proxy {
+	p := &proxy{
+		upstream: upstream,
+		apiKey:   apiKey,
+		doer:     doer,
+		cache:    cache,
+		log:      logger,
+		now:      time.Now,
+		clients:  map[string]Client{},
+		usage:    map[string]*usage{},
+	}
+	for _, c := range clients {
+		p.clients[c.Token] = c
+	}
+	return p
+}
+
+// statusWriter records the response status for access logs.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := p.now()
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	client, cache := p.serve(sw, r)
+	if client == "" {
+		client = "-"
+	}
+	p.log.Printf("%s %s %s %s %d %s %s",
+		r.RemoteAddr, client, r.Method, r.URL.RequestURI(), sw.status, cache, p.now().Sub(start))
+}
+
+// serve handles the request and returns the client name and cache status
+// for the access log.
+func (p *proxy) serve(w http.ResponseWriter, r *http.Request) (client, cache string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Only GET requests are supported")
+		return "", "-"
+	}
+	// Dot segments are removed before the prefix check, so that requests
+	// with the real api key cannot leave the API version path.
+	path := strings.TrimPrefix(pathpkg.Clean(r.URL.Path), "/v2/")
+	if strings.HasPrefix(path, "/") {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "Not found")
+		return "", "-"
+	}
+
+	c, ok := p.clients[r.Header.Get("apiKey")]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "INVALID_API_KEY", "Invalid authentication credentials")
+		return "", "-"
+	}
+	if !p.allow(w, c) {
+		writeError(w, http.StatusTooManyRequests, "TOO_MANY_REQUESTS", "Rate limit exceeded")
+		return c.Name, "-"
+	}
+
+	q := r.URL.Query()
+	u := p.upstream.ResolveReference(&url.URL{Path: path, RawQuery: q.Encode()})
+	lang := r.Header.Get("Accept-Language")
+	key := u.String() + " " + lang
+
+	now := p.now()
+	if e, ok := p.cache.Get(key); ok && now.Before(e.Expires) {
+		writeJSON(w, e.Body)
+		return c.Name, "HIT"
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return c.Name, "MISS"
+	}
+	req.Header.Set("apiKey", p.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if lang != "" {
+		req.Header.Set("Accept-Language", lang)
+	}
+
+	resp, err := p.doer.Do(req)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "BAD_GATEWAY", "Airly API is unreachable")
+		return c.Name, "MISS"
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "BAD_GATEWAY", "Reading Airly API response failed")
+		return c.Name, "MISS"
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		w.Write(body)
+		return c.Name, "MISS"
+	}
+
+	// Airly publishes new data every hour, so responses are cached
+	// until the end of the current hourly window.
+	p.cache.Set(key, airly.CacheEntry{
+		Body:    body,
+		Stored:  now,
+		Expires: now.Truncate(time.Hour).Add(time.Hour),
+	})
+	writeJSON(w, body)
+	return c.Name, "MISS"
+}
+
+// allow counts the request against the client's daily quota and sets
+// rate limit headers. It reports whether the request is within the quota.
+func (p *proxy) allow(w http.ResponseWriter, c Client) bool {
+	if c.DailyQuota <= 0 {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	day := p.now().UTC().Truncate(24 * time.Hour)
+	u, ok := p.usage[c.Token]
+	if !ok || !u.day.Equal(day) {
+		u = &usage{day: day}
+		p.usage[c.Token] = u
+	}
+
+	w.Header().Set("X-RateLimit-Limit-day", strconv.Itoa(c.DailyQuota))
+	if u.used >= c.DailyQuota {
+		w.Header().Set("X-RateLimit-Remaining-day", "0")
+		return false
+	}
+	u.used++
+	w.Header().Set("X-RateLimit-Remaining-day", strconv.Itoa(c.DailyQuota-u.used))
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(airly.Error{ErrorCode: code, Message: msg})
+}
+
+func loadClients(path string) ([]Client, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read clients: %w", err)
+	}
+	var clients []Client
+	if err := json.Unmarshal(b, &clients); err != nil {
+		return nil, fmt.Errorf("decode clients: %w", err)
+	}
+	for _, c := range clients {
+		if c.Token == "" {
+			return nil, fmt.Errorf("client %q has no token", c.Name)
+		}
+	}
+	return clients, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lsjurczak/go-airly"
+	"github.com/lsjurczak/go-airly/airlytest"
+)
+
+func setup(t *testing.T, clients ...Client) (*airlytest.Server, *proxy, *httptest.Server, *bytes.Buffer) {
+	t.Helper()
+	upstream := airlytest.NewServer(airlytest.DefaultDataset())
+	u, _ := url.Parse(upstream.URL)
+	var logs bytes.Buffer
+	p := newProxy(u, airlytest.APIKey, clients, http.DefaultClient,
+		airly.NewMemoryCache(100, 0), log.New(&logs, "", 0))
+	return upstream, p, httptest.NewServer(p), &logs
+}
+
+func newClient(t *testing.T, srv *httptest.Server, token string) *airly.Client {
+	t.Helper()
+	c, err := airly.NewClient(srv.Client(), token)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	u, _ := url.Parse(srv.URL + "/v2/")
+	return c.BaseURL(u)
+}
+
+func TestProxy(t *testing.T) {
+	upstream, p, srv, logs := setup(t, Client{Name: "web", Token: "web-token"})
+	defer upstream.Close()
+	defer srv.Close()
+
+	now := time.Date(2020, 5, 7, 15, 10, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	client := newClient(t, srv, "web-token")
+	opt := airly.NewByIDMeasurementOpts(8077)
+
+	for i := 0; i < 2; i++ {
+		m, err := client.Measurement.ByID(context.Background(), opt)
+		if err != nil {
+			t.Fatalf("Measurement.ByID: %v", err)
+		}
+		if m.Current.TillDateTime.IsZero() {
+			t.Errorf("Measurement.ByID returned %+v", m)
+		}
+	}
+	if got := upstream.Requests(); got != 1 {
+		t.Errorf("upstream received %d requests, want 1", got)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := client.Measurement.ByID(context.Background(), opt); err != nil {
+		t.Fatalf("Measurement.ByID: %v", err)
+	}
+	if got := upstream.Requests(); got != 2 {
+		t.Errorf("upstream received %d requests in next hour, want 2", got)
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], " web GET /v2/measurements/installation?installationId=8077 200 HIT ") {
+		t.Errorf("access logs:\n%s", logs.String())
+	}
+}
+
+func TestProxy_auth(t *testing.T) {
+	upstream, _, srv, _ := setup(t, Client{Name: "web", Token: "web-token", DailyQuota: 1})
+	defer upstream.Close()
+	defer srv.Close()
+
+	_, err := newClient(t, srv, airlytest.APIKey).Meta.Indexes(context.Background())
+	var e airly.Error
+	if !errors.As(err, &e) || e.ErrorCode != "INVALID_API_KEY" {
+		t.Errorf("request with upstream key returned %v, want INVALID_API_KEY", err)
+	}
+
+	client := newClient(t, srv, "web-token")
+	if _, err := client.Meta.Indexes(context.Background()); err != nil {
+		t.Fatalf("Meta.Indexes: %v", err)
+	}
+	_, err = client.Meta.Indexes(context.Background())
+	if !errors.As(err, &e) || e.ErrorCode != "TOO_MANY_REQUESTS" {
+		t.Errorf("request over quota returned %v, want TOO_MANY_REQUESTS", err)
+	}
+}
+
+func TestProxy_pathOutsideAPI(t *testing.T) {
+	upstream, p, srv, _ := setup(t, Client{Name: "web", Token: "web-token"})
+	defer upstream.Close()
+	defer srv.Close()
+
+	for _, target := range []string{"/v2/../anything", "/v2/%2e%2e/anything", "/v2/meta/../../anything", "/v2/", "/meta/indexes"} {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("apiKey", "web-token")
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("GET %s returned %d, want 404", target, w.Code)
+		}
+	}
+	if got := upstream.Requests(); got != 0 {
+		t.Errorf("upstream received %d requests, want 0", got)
+	}
+}
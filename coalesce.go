@@ -0,0 +1,122 @@
+package airly
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// CoalesceRequests enables sharing of in-flight requests: concurrent calls
+// for the same path, query and language wait for a single request and
+// receive the same decoded result, including its slices, which callers
+// must not modify.
+//
+// Canceling the context of one caller only stops it from waiting;
+// the shared request is canceled when all of its callers gave up.
+func (c *Client) CoalesceRequests(enabled bool) *Client {
+	c.coalesce = enabled
+	return c
+}
+
+// sharedResult is the outcome of a coalesced request.
+type sharedResult struct {
+	val  interface{}
+	resp *Response
+}
+
+func (c *Client) coalescedGet(ctx context.Context, req *http.Request, result interface{}) (*Response, error) {
+	typ := reflect.TypeOf(result).Elem()
+	v, err := c.inflight.do(ctx, cacheKey(req), func(ctx context.Context) (interface{}, error) {
+		val := reflect.New(typ).Interface()
+		resp, err := c.load(req.WithContext(ctx), val)
+		return sharedResult{val: val, resp: resp}, err
+	})
+	shared, _ := v.(sharedResult)
+	var resp *Response
+	if shared.resp != nil {
+		r := *shared.resp
+		resp = &r
+	}
+	if err != nil {
+		return resp, err
+	}
+	reflect.ValueOf(result).Elem().Set(reflect.ValueOf(shared.val).Elem())
+	return resp, nil
+}
+
+// call is an in-flight or completed group.do call.
+type call struct {
+	done    chan struct{}
+	val     interface{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// group deduplicates concurrent calls with the same key.
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// do runs fn once for all concurrent callers with the same key.
+// fn runs with a context carrying the values of the first caller's context,
+// which is canceled once every caller's context is done.
+func (g *group) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*call{}
+	}
+	cl, ok := g.calls[key]
+	if !ok {
+		// The values of the first caller's context, such as the trace
+		// context, are kept, but not its cancellation.
+		fnCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cl = &call{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = cl
+		go func() {
+			cl.val, cl.err = fn(fnCtx)
+			g.forget(key, cl)
+			cancel()
+			close(cl.done)
+		}()
+	}
+	cl.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.val, cl.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		cl.waiters--
+		if cl.waiters == 0 {
+			cl.cancel()
+			if g.calls[key] == cl {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// waiters returns the number of callers waiting for all in-flight calls.
+func (g *group) waiters() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n := 0
+	for _, cl := range g.calls {
+		n += cl.waiters
+	}
+	return n
+}
+
+func (g *group) forget(key string, cl *call) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls[key] == cl {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,130 @@
+package airly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_CoalesceRequests(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.CoalesceRequests(true)
+
+	var requests int32
+	release := make(chan struct{})
+	mux.HandleFunc("/measurements/installation", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		fmt.Fprint(w, mockMeasurementResponse)
+	})
+
+	const callers = 10
+	canceled, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	errs := make([]error, callers)
+	results := make([]Measurement, callers)
+	for i := 0; i < callers; i++ {
+		ctx := context.Background()
+		if i == 0 {
+			ctx = canceled
+		}
+		wg.Add(1)
+		go func(i int, ctx context.Context) {
+			defer wg.Done()
+			results[i], errs[i] = client.Measurement.ByID(ctx, NewByIDMeasurementOpts(6600))
+		}(i, ctx)
+	}
+
+	waitForWaiters(t, &client.inflight, callers)
+	cancel()
+	waitForWaiters(t, &client.inflight, callers-1)
+	close(release)
+	wg.Wait()
+
+	if !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("canceled caller returned %v, want context.Canceled", errs[0])
+	}
+	for i := 1; i < callers; i++ {
+		if errs[i] != nil {
+			t.Errorf("caller %d: %v", i, errs[i])
+		}
+		if !reflect.DeepEqual(results[i], mockMeasurement) {
+			t.Errorf("caller %d got %+v, want %+v", i, results[i], mockMeasurement)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("sent %d requests, want 1", got)
+	}
+}
+
+func TestClient_CoalesceRequests_contextValues(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.CoalesceRequests(true)
+
+	type key struct{}
+	var got interface{}
+	client.Use(func(next HTTPDoer) HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			got = req.Context().Value(key{})
+			return next.Do(req)
+		})
+	})
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, mockIndexesResponse)
+	})
+
+	ctx := context.WithValue(context.Background(), key{}, "trace")
+	if _, err := client.Meta.Indexes(ctx); err != nil {
+		t.Fatalf("Meta.Indexes: %v", err)
+	}
+	if got != "trace" {
+		t.Errorf("middleware got context value %v, want trace", got)
+	}
+}
+
+func TestGroup_allCallersCanceled(t *testing.T) {
+	var g group
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	started := make(chan struct{})
+
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := g.do(ctx, "key", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("do returned %v, want context.Canceled", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("shared call was not canceled")
+	}
+}
+
+// waitForWaiters waits until n callers wait for the calls of g.
+func waitForWaiters(t *testing.T, g *group, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for g.waiters() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d callers waiting, want %d", g.waiters(), n)
+		}
+		runtime.Gosched()
+	}
+}
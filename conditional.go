@@ -0,0 +1,72 @@
+package airly
+
+import (
+	"net/http"
+)
+
+// ConditionalRequests enables revalidation of responses that carry an ETag
+// or Last-Modified header. The last such response of every request is kept
+// in store, and subsequent requests send If-None-Match and
+// If-Modified-Since; a 304 Not Modified response then returns the stored
+// body with StatusCode 304. Pass a nil store to disable it.
+//
+// store may be shared with Cache, e.g. NewMemoryCache(1000, 0) bounds
+// both by the number of entries.
+func (c *Client) ConditionalRequests(store Cache) *Client {
+	c.validators = store
+	return c
+}
+
+func validatorKey(req *http.Request) string {
+	return "validators " + cacheKey(req)
+}
+
+// hasValidators reports whether h can be used for a conditional request.
+func hasValidators(h http.Header) bool {
+	return h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+}
+
+// conditional returns req with the validators of the stored response set,
+// and the stored response if there is one.
+func (c *Client) conditional(req *http.Request) (*http.Request, CacheEntry, bool) {
+	if c.validators == nil {
+		return req, CacheEntry{}, false
+	}
+	prev, ok := c.validators.Get(validatorKey(req))
+	if !ok {
+		return req, CacheEntry{}, false
+	}
+	req = req.Clone(req.Context())
+	if etag := prev.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified := prev.Header.Get("Last-Modified"); modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+	return req, prev, true
+}
+
+// storeValidators keeps a successful response for revalidation. A 304
+// response updates the validators of prev, as the server may send new ones.
+func (c *Client) storeValidators(req *http.Request, resp *http.Response, body []byte, prev CacheEntry) {
+	if c.validators == nil {
+		return
+	}
+	header := resp.Header
+	if resp.StatusCode == http.StatusNotModified {
+		header = prev.Header.Clone()
+		for _, k := range []string{"ETag", "Last-Modified"} {
+			if v := resp.Header.Get(k); v != "" {
+				header.Set(k, v)
+			}
+		}
+	}
+	if !hasValidators(header) {
+		return
+	}
+	c.validators.Set(validatorKey(req), CacheEntry{
+		Body:   body,
+		Header: header,
+		Stored: c.now(),
+	})
+}
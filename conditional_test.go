@@ -0,0 +1,87 @@
+package airly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClient_ConditionalRequests(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.ConditionalRequests(NewMemoryCache(10, 0))
+
+	const (
+		etag     = `"v1"`
+		modified = "Thu, 07 May 2020 15:00:00 GMT"
+	)
+	var requests int
+	mux.HandleFunc("/measurements/installation", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			if got := r.Header.Get("If-None-Match"); got != etag {
+				t.Errorf("If-None-Match = %q, want %q", got, etag)
+			}
+			if got := r.Header.Get("If-Modified-Since"); got != modified {
+				t.Errorf("If-Modified-Since = %q, want %q", got, modified)
+			}
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", modified)
+		fmt.Fprint(w, mockMeasurementResponse)
+	})
+
+	opts := NewByIDMeasurementOpts(6600)
+	if _, err := client.Measurement.ByID(context.Background(), opts); err != nil {
+		t.Fatalf("Measurement.ByID returned error: %v", err)
+	}
+	got, resp, err := client.Measurement.ByIDWithResponse(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Measurement.ByID returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, mockMeasurement) {
+		t.Errorf("Measurement.ByID returned %+v, want %+v", got, mockMeasurement)
+	}
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusNotModified)
+	}
+	if requests != 2 {
+		t.Errorf("sent %d requests, want 2", requests)
+	}
+}
+
+func TestClient_ConditionalRequests_withoutValidators(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.ConditionalRequests(NewMemoryCache(10, 0))
+
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			t.Errorf("unexpected conditional request: %v", r.Header)
+		}
+		fmt.Fprint(w, mockIndexesResponse)
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Meta.Indexes(context.Background()); err != nil {
+			t.Fatalf("Meta.Indexes returned error: %v", err)
+		}
+	}
+}
+
+func TestClient_notModifiedWithoutStoredResponse(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	})
+
+	if _, err := client.Meta.Indexes(context.Background()); err == nil {
+		t.Error("Meta.Indexes returned nil error for an unexpected 304")
+	}
+}
@@ -0,0 +1,121 @@
+// Package correction corrects particulate matter concentrations measured
+// by optical sensors, which over-read when the air is humid.
+package correction
+
+import (
+	"math"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+const (
+	defaultKappa       = 0.4
+	defaultDensity     = 1.65
+	defaultMaxHumidity = 95
+)
+
+// Corrector corrects the value named name, e.g. "PM25", measured at
+// the relative humidity in %. It reports false for values it does not
+// correct.
+type Corrector interface {
+	Correct(name string, value, humidity float64) (float64, bool)
+}
+
+// CorrectorFunc is an adapter to allow the use of ordinary functions
+// as Corrector.
+type CorrectorFunc func(name string, value, humidity float64) (float64, bool)
+
+// Correct calls f(name, value, humidity).
+func (f CorrectorFunc) Correct(name string, value, humidity float64) (float64, bool) {
+	return f(name, value, humidity)
+}
+
+// Kohler removes the water taken up by particles using the κ-Köhler theory,
+// as in Crilley et al. (2018), https://doi.org/10.5194/amt-11-709-2018.
+// It corrects PM1, PM2.5 and PM10.
+type Kohler struct {
+	// Kappa is the hygroscopicity of the particles, 0.4 if zero.
+	Kappa float64
+	// Density is the density of the dry particles in g/cm³, 1.65 if zero.
+	Density float64
+	// MaxHumidity caps the humidity in %, as the correction grows without
+	// bound near saturation, 95 if zero.
+	MaxHumidity float64
+}
+
+// Correct implements Corrector.
+func (k Kohler) Correct(name string, value, humidity float64) (float64, bool) {
+	if name != "PM1" && name != "PM25" && name != "PM10" {
+		return 0, false
+	}
+	kappa := orDefault(k.Kappa, defaultKappa)
+	density := orDefault(k.Density, defaultDensity)
+	aw := math.Min(humidity, orDefault(k.MaxHumidity, defaultMaxHumidity)) / 100
+	if aw <= 0 {
+		return value, true
+	}
+	growth := 1 + (kappa/density)/(1/aw-1)
+	return value / growth, true
+}
+
+// EPA is the US EPA correction of PurpleAir PM2.5 sensors by
+// Barkjohn et al. (2021), https://doi.org/10.5194/amt-14-4617-2021:
+// 0.524×PM2.5 − 0.0862×RH + 5.75. It corrects PM2.5 only and does not
+// return negative concentrations.
+type EPA struct{}
+
+// Correct implements Corrector.
+func (EPA) Correct(name string, value, humidity float64) (float64, bool) {
+	if name != "PM25" {
+		return 0, false
+	}
+	return math.Max(0.524*value-0.0862*humidity+5.75, 0), true
+}
+
+// Data returns a copy of d with the values corrected by c using the
+// HUMIDITY value of d. Values of data without humidity are copied
+// unchanged. d is not modified, so the original values stay available.
+func Data(d airly.Data, c Corrector) airly.Data {
+	humidity, ok := d.Value("HUMIDITY")
+	if !ok {
+		d.Values = append([]airly.Value(nil), d.Values...)
+		return d
+	}
+	values := make([]airly.Value, len(d.Values))
+	for i, v := range d.Values {
+		if corrected, ok := c.Correct(v.Name, v.Value, humidity); ok {
+			v.Value = math.Round(corrected*100) / 100
+		}
+		values[i] = v
+	}
+	d.Values = values
+	return d
+}
+
+// Measurement returns a copy of m with the Current, History and Forecast
+// values corrected by c, see Data. m is not modified.
+func Measurement(m airly.Measurement, c Corrector) airly.Measurement {
+	out := m
+	out.Current = Data(m.Current, c)
+	out.History = dataSlice(m.History, c)
+	out.Forecast = dataSlice(m.Forecast, c)
+	return out
+}
+
+func dataSlice(data []airly.Data, c Corrector) []airly.Data {
+	if data == nil {
+		return nil
+	}
+	out := make([]airly.Data, len(data))
+	for i, d := range data {
+		out[i] = Data(d, c)
+	}
+	return out
+}
+
+func orDefault(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,122 @@
+package correction
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+func TestKohler(t *testing.T) {
+	// Growth factor 1 + (0.4/1.65) / (100/RH - 1).
+	tests := []struct {
+		name     string
+		value    float64
+		humidity float64
+		want     float64
+	}{
+		{"PM25", 50, 50, 40.2439},
+		{"PM25", 50, 90, 15.7143},
+		{"PM10", 80, 70, 51.0968},
+		{"PM1", 10, 0, 10},
+		// Humidity is capped at 95%.
+		{"PM25", 50, 99, 8.9189},
+	}
+	for _, tt := range tests {
+		got, ok := Kohler{}.Correct(tt.name, tt.value, tt.humidity)
+		if !ok || math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("Correct(%s, %v, %v) = %v, %v, want %v", tt.name, tt.value, tt.humidity, got, ok, tt.want)
+		}
+	}
+	if _, ok := (Kohler{}).Correct("TEMPERATURE", 20, 50); ok {
+		t.Error("Correct corrected TEMPERATURE")
+	}
+}
+
+func TestKohler_properties(t *testing.T) {
+	// Dry particles do not grow, and wetter air inflates readings more,
+	// so corrections fall monotonically with humidity and never raise values.
+	prev := math.Inf(1)
+	for rh := 0.0; rh <= 100; rh += 5 {
+		got, _ := Kohler{}.Correct("PM25", 50, rh)
+		if got > prev || got > 50 {
+			t.Errorf("Correct(PM25, 50, %v) = %v after %v", rh, got, prev)
+		}
+		prev = got
+	}
+	// More hygroscopic particles are corrected more.
+	low, _ := Kohler{Kappa: 0.2}.Correct("PM25", 50, 80)
+	high, _ := Kohler{Kappa: 0.6}.Correct("PM25", 50, 80)
+	if high >= low {
+		t.Errorf("κ 0.6 corrected to %v, not below κ 0.2 %v", high, low)
+	}
+}
+
+func TestEPA(t *testing.T) {
+	tests := []struct {
+		value    float64
+		humidity float64
+		want     float64
+	}{
+		{20, 50, 11.92},
+		{100, 80, 51.254},
+		{35, 30, 21.504},
+		{0, 90, 0},
+	}
+	for _, tt := range tests {
+		got, ok := EPA{}.Correct("PM25", tt.value, tt.humidity)
+		if !ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Correct(PM25, %v, %v) = %v, %v, want %v", tt.value, tt.humidity, got, ok, tt.want)
+		}
+	}
+	if _, ok := (EPA{}).Correct("PM10", 20, 50); ok {
+		t.Error("Correct corrected PM10")
+	}
+}
+
+func TestMeasurement(t *testing.T) {
+	m := airly.Measurement{
+		Current: airly.Data{Values: []airly.Value{
+			{Name: "PM25", Value: 20},
+			{Name: "PM10", Value: 30},
+			{Name: "HUMIDITY", Value: 50},
+		}},
+		Forecast: []airly.Data{{Values: []airly.Value{{Name: "PM25", Value: 20}}}},
+	}
+	original := airly.Measurement{
+		Current:  airly.Data{Values: append([]airly.Value(nil), m.Current.Values...)},
+		Forecast: []airly.Data{{Values: append([]airly.Value(nil), m.Forecast[0].Values...)}},
+	}
+
+	got := Measurement(m, EPA{})
+	want := []airly.Value{
+		{Name: "PM25", Value: 11.92},
+		{Name: "PM10", Value: 30},
+		{Name: "HUMIDITY", Value: 50},
+	}
+	if !reflect.DeepEqual(got.Current.Values, want) {
+		t.Errorf("Measurement returned %+v, want %+v", got.Current.Values, want)
+	}
+	// The forecast has no humidity to correct with.
+	if !reflect.DeepEqual(got.Forecast, original.Forecast) {
+		t.Errorf("Measurement returned forecast %+v, want %+v", got.Forecast, original.Forecast)
+	}
+	if !reflect.DeepEqual(m, original) {
+		t.Errorf("Measurement modified the original: %+v", m)
+	}
+	got.Forecast[0].Values[0].Value = 99
+	if m.Forecast[0].Values[0].Value != 20 {
+		t.Error("Measurement shares the values of data without humidity")
+	}
+}
+
+func TestCorrectorFunc(t *testing.T) {
+	double := CorrectorFunc(func(name string, value, humidity float64) (float64, bool) {
+		return value * 2, name == "PM10"
+	})
+	d := Data(airly.Data{Values: []airly.Value{{Name: "PM10", Value: 5}, {Name: "HUMIDITY", Value: 40}}}, double)
+	if d.Values[0].Value != 10 || d.Values[1].Value != 40 {
+		t.Errorf("Data returned %+v", d.Values)
+	}
+}
@@ -0,0 +1,151 @@
+package airly
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+//go:generate go run ./cmd/airly-meta-snapshot -o metadata/snapshot.json
+
+//go:embed metadata/snapshot.json
+var embeddedSnapshot []byte
+
+var (
+	embeddedOnce sync.Once
+	embeddedMeta MetaSnapshot
+)
+
+// MetaSnapshot holds the responses of MetaService by language.
+type MetaSnapshot struct {
+	// Version is the date the snapshot was taken from the API, prefixed
+	// with "docs-" when it was assembled from the API documentation.
+	Version   string              `json:"version"`
+	Languages map[string]MetaData `json:"languages"`
+}
+
+// MetaData holds the responses of MetaService in a single language.
+type MetaData struct {
+	Indexes      []IndexType       `json:"indexes"`
+	Measurements []MeasurementType `json:"measurements"`
+}
+
+// EmbeddedMeta returns the snapshot of MetaService responses bundled
+// with the package.
+func EmbeddedMeta() MetaSnapshot {
+	embeddedOnce.Do(func() {
+		if err := json.Unmarshal(embeddedSnapshot, &embeddedMeta); err != nil {
+			panic(fmt.Sprintf("airly: invalid embedded meta snapshot: %v", err))
+		}
+	})
+	s := MetaSnapshot{
+		Version:   embeddedMeta.Version,
+		Languages: make(map[string]MetaData, len(embeddedMeta.Languages)),
+	}
+	for lang, data := range embeddedMeta.Languages {
+		s.Languages[lang] = data.clone()
+	}
+	return s
+}
+
+func (d MetaData) clone() MetaData {
+	out := MetaData{
+		Indexes:      make([]IndexType, len(d.Indexes)),
+		Measurements: append([]MeasurementType(nil), d.Measurements...),
+	}
+	for i, idx := range d.Indexes {
+		idx.Levels = append([]Level(nil), idx.Levels...)
+		out.Indexes[i] = idx
+	}
+	return out
+}
+
+// PreferEmbeddedMeta makes MetaService return the embedded snapshot in the
+// client's language instead of calling the API. Regardless of it, the
+// snapshot is returned when the API cannot be reached.
+func (c *Client) PreferEmbeddedMeta(enabled bool) *Client {
+	c.preferEmbeddedMeta = enabled
+	return c
+}
+
+// embeddedMetaData returns the embedded snapshot in the language
+// of requests sent with ctx.
+func (c *Client) embeddedMetaData(ctx context.Context) (MetaData, *Response, bool) {
+	lang := c.requestLanguage(ctx)
+	if lang == "" {
+		lang = English
+	}
+	data, ok := EmbeddedMeta().Languages[string(lang)]
+	if !ok {
+		return MetaData{}, nil, false
+	}
+	return data, &Response{Embedded: true, RateLimits: RateLimits{Day: Rate{-1, -1}, Minute: Rate{-1, -1}}}, true
+}
+
+// preferredMeta returns the embedded snapshot if PreferEmbeddedMeta is set.
+func (c *Client) preferredMeta(ctx context.Context) (MetaData, *Response, bool) {
+	if !c.preferEmbeddedMeta {
+		return MetaData{}, nil, false
+	}
+	return c.embeddedMetaData(ctx)
+}
+
+// offlineMeta returns the embedded snapshot if err is a network error,
+// which means the API cannot be reached.
+func (c *Client) offlineMeta(ctx context.Context, resp *Response, err error) (MetaData, *Response, bool) {
+	var verr ValidationError
+	if err == nil || resp != nil || ctx.Err() != nil || errors.As(err, &verr) {
+		return MetaData{}, nil, false
+	}
+	return c.embeddedMetaData(ctx)
+}
+
+// RefreshMetaSnapshot fetches the responses of MetaService in languages
+// from the API, bypassing the client's cache and the embedded snapshot.
+// version is the version stamp of the snapshot, e.g. the current date.
+func (c *Client) RefreshMetaSnapshot(ctx context.Context, version string, languages ...Language) (MetaSnapshot, error) {
+	s := MetaSnapshot{Version: version, Languages: make(map[string]MetaData, len(languages))}
+	for _, lang := range languages {
+		ctx := WithLanguage(ctx, lang)
+		var data MetaData
+		if _, err := c.fetchNow(ctx, "meta/indexes", &data.Indexes); err != nil {
+			return MetaSnapshot{}, fmt.Errorf("indexes in %q: %w", lang, err)
+		}
+		if _, err := c.fetchNow(ctx, "meta/measurements", &data.Measurements); err != nil {
+			return MetaSnapshot{}, fmt.Errorf("measurements in %q: %w", lang, err)
+		}
+		s.Languages[string(lang)] = data
+	}
+	return s, nil
+}
+
+// fetchNow sends the request without using the cache.
+func (c *Client) fetchNow(ctx context.Context, path string, result interface{}) (*Response, error) {
+	req, err := c.newRequest(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, resp, err := c.do(req)
+	if err != nil {
+		return resp, err
+	}
+	return resp, decode(body, result)
+}
+
+// WriteFile writes the snapshot as JSON to path, e.g. to replace
+// metadata/snapshot.json before building the package.
+func (s MetaSnapshot) WriteFile(path string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,137 @@
+package airly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEmbeddedMeta(t *testing.T) {
+	s := EmbeddedMeta()
+	if s.Version == "" {
+		t.Error("embedded snapshot has no version")
+	}
+	for _, lang := range []string{"en", "pl"} {
+		data, ok := s.Languages[lang]
+		if !ok || len(data.Indexes) == 0 || len(data.Measurements) == 0 {
+			t.Errorf("embedded snapshot in %q is %+v", lang, data)
+		}
+		for _, name := range []IndexKind{AirlyCAQI, CAQI, PIJP} {
+			if _, err := NewIndexScale(data.Indexes, string(name)); err != nil {
+				t.Errorf("embedded snapshot in %q: %v", lang, err)
+			}
+		}
+	}
+
+	s.Languages["en"].Indexes[0].Levels[0].Color = "#000000"
+	if got := EmbeddedMeta().Languages["en"].Indexes[0].Levels[0].Color; got == "#000000" {
+		t.Error("EmbeddedMeta returned a shared snapshot")
+	}
+}
+
+func TestMetaService_PreferEmbeddedMeta(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.PreferEmbeddedMeta(true).Language("pl")
+
+	mux.HandleFunc("/meta/measurements", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent with PreferEmbeddedMeta")
+	})
+
+	got, resp, err := client.Meta.MeasurementsWithResponse(context.Background())
+	if err != nil {
+		t.Fatalf("Meta.Measurements returned error: %v", err)
+	}
+	if want := EmbeddedMeta().Languages["pl"].Measurements; !reflect.DeepEqual(got, want) {
+		t.Errorf("Meta.Measurements returned %+v, want %+v", got, want)
+	}
+	if !resp.Embedded {
+		t.Error("Response.Embedded is false")
+	}
+}
+
+func TestMetaService_offline(t *testing.T) {
+	offline := DoerFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("network is unreachable")
+	})
+	client, err := NewClient(offline, "apiKey")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	got, err := client.Meta.Indexes(context.Background())
+	if err != nil {
+		t.Fatalf("Meta.Indexes returned error: %v", err)
+	}
+	if want := EmbeddedMeta().Languages["en"].Indexes; !reflect.DeepEqual(got, want) {
+		t.Errorf("Meta.Indexes returned %+v, want %+v", got, want)
+	}
+
+	// Unsupported languages are rejected instead of falling back.
+	ctx := WithLanguage(context.Background(), "de")
+	var verr ValidationError
+	if _, err := client.Meta.Indexes(ctx); !errors.As(err, &verr) {
+		t.Errorf("Meta.Indexes in an unsupported language returned %v, want ValidationError", err)
+	}
+}
+
+func TestMetaService_serverErrorNotEmbedded(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"errorCode":"UNAVAILABLE"}`)
+	})
+
+	if _, err := client.Meta.Indexes(context.Background()); !hasErrorCode(err, "UNAVAILABLE") {
+		t.Errorf("Meta.Indexes returned %v, want UNAVAILABLE", err)
+	}
+}
+
+func TestClient_RefreshMetaSnapshot(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.Cache(NewMemoryCache(10, 0), CacheOptions{}).PreferEmbeddedMeta(true)
+
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, mockIndexesResponse)
+	})
+	mux.HandleFunc("/meta/measurements", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"name":"PM1","label":"PM1 %s","unit":"µg/m³"}]`, r.Header.Get("Accept-Language"))
+	})
+
+	s, err := client.RefreshMetaSnapshot(context.Background(), "2020-05-07", "en", "pl")
+	if err != nil {
+		t.Fatalf("RefreshMetaSnapshot returned error: %v", err)
+	}
+	want := MetaSnapshot{
+		Version: "2020-05-07",
+		Languages: map[string]MetaData{
+			"en": {Indexes: mockIndexes, Measurements: []MeasurementType{{Name: "PM1", Label: "PM1 en", Unit: "µg/m³"}}},
+			"pl": {Indexes: mockIndexes, Measurements: []MeasurementType{{Name: "PM1", Label: "PM1 pl", Unit: "µg/m³"}}},
+		},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("RefreshMetaSnapshot returned %+v, want %+v", s, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := s.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var read MetaSnapshot
+	if err := json.Unmarshal(b, &read); err != nil || !reflect.DeepEqual(read, want) {
+		t.Errorf("WriteFile wrote %s", b)
+	}
+}
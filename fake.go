@@ -0,0 +1,176 @@
+package airly
+
+import (
+	"context"
+	"sync"
+)
+
+// Call records a single invocation of a fake service method.
+type Call struct {
+	// Method is the name of the called method, e.g. "ByID".
+	Method string
+	// Args holds the arguments in the order they were passed.
+	Args []interface{}
+}
+
+type fakeCalls struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (f *fakeCalls) record(method string, args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns all recorded calls in the order they were made.
+func (f *fakeCalls) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := make([]Call, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+// Reset forgets all recorded calls.
+func (f *fakeCalls) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+// FakeInstallationService is an InstallationAPI for use in tests.
+// Responses are programmed with the Func fields; a nil Func returns zero values.
+// Err, when set, is returned by every method instead.
+// Invalid opts are refused the same way InstallationService refuses them.
+type FakeInstallationService struct {
+	ByIDFunc    func(ctx context.Context, id int64) (Installation, error)
+	NearestFunc func(ctx context.Context, opts *NearestInstallationOpts) ([]Installation, error)
+	Err         error
+
+	fakeCalls
+}
+
+var _ InstallationAPI = (*FakeInstallationService)(nil)
+
+// ByID records the call and returns the programmed response.
+func (f *FakeInstallationService) ByID(ctx context.Context, id int64) (Installation, error) {
+	f.record("ByID", id)
+	if f.Err != nil {
+		return Installation{}, f.Err
+	}
+	if f.ByIDFunc == nil {
+		return Installation{}, nil
+	}
+	return f.ByIDFunc(ctx, id)
+}
+
+// Nearest records the call and returns the programmed response.
+func (f *FakeInstallationService) Nearest(ctx context.Context, opts *NearestInstallationOpts) ([]Installation, error) {
+	f.record("Nearest", opts)
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.NearestFunc == nil {
+		return nil, nil
+	}
+	return f.NearestFunc(ctx, opts)
+}
+
+// FakeMeasurementService is a MeasurementAPI for use in tests.
+// It behaves like FakeInstallationService.
+type FakeMeasurementService struct {
+	ByIDFunc     func(ctx context.Context, opts *ByIDMeasurementOpts) (Measurement, error)
+	NearestFunc  func(ctx context.Context, opts *NearestMeasurementOpts) (Measurement, error)
+	ForPointFunc func(ctx context.Context, opts *ForPointMeasurementOpts) (Measurement, error)
+	Err          error
+
+	fakeCalls
+}
+
+var _ MeasurementAPI = (*FakeMeasurementService)(nil)
+
+// ByID records the call and returns the programmed response.
+func (f *FakeMeasurementService) ByID(ctx context.Context, opts *ByIDMeasurementOpts) (Measurement, error) {
+	f.record("ByID", opts)
+	if err := opts.Validate(); err != nil {
+		return Measurement{}, err
+	}
+	if f.Err != nil {
+		return Measurement{}, f.Err
+	}
+	if f.ByIDFunc == nil {
+		return Measurement{}, nil
+	}
+	return f.ByIDFunc(ctx, opts)
+}
+
+// Nearest records the call and returns the programmed response.
+func (f *FakeMeasurementService) Nearest(ctx context.Context, opts *NearestMeasurementOpts) (Measurement, error) {
+	f.record("Nearest", opts)
+	if err := opts.Validate(); err != nil {
+		return Measurement{}, err
+	}
+	if f.Err != nil {
+		return Measurement{}, f.Err
+	}
+	if f.NearestFunc == nil {
+		return Measurement{}, nil
+	}
+	return f.NearestFunc(ctx, opts)
+}
+
+// ForPoint records the call and returns the programmed response.
+func (f *FakeMeasurementService) ForPoint(ctx context.Context, opts *ForPointMeasurementOpts) (Measurement, error) {
+	f.record("ForPoint", opts)
+	if err := opts.Validate(); err != nil {
+		return Measurement{}, err
+	}
+	if f.Err != nil {
+		return Measurement{}, f.Err
+	}
+	if f.ForPointFunc == nil {
+		return Measurement{}, nil
+	}
+	return f.ForPointFunc(ctx, opts)
+}
+
+// FakeMetaService is a MetaAPI for use in tests.
+// It behaves like FakeInstallationService.
+type FakeMetaService struct {
+	IndexesFunc      func(ctx context.Context) ([]IndexType, error)
+	MeasurementsFunc func(ctx context.Context) ([]MeasurementType, error)
+	Err              error
+
+	fakeCalls
+}
+
+var _ MetaAPI = (*FakeMetaService)(nil)
+
+// Indexes records the call and returns the programmed response.
+func (f *FakeMetaService) Indexes(ctx context.Context) ([]IndexType, error) {
+	f.record("Indexes")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.IndexesFunc == nil {
+		return nil, nil
+	}
+	return f.IndexesFunc(ctx)
+}
+
+// Measurements records the call and returns the programmed response.
+func (f *FakeMetaService) Measurements(ctx context.Context) ([]MeasurementType, error) {
+	f.record("Measurements")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.MeasurementsFunc == nil {
+		return nil, nil
+	}
+	return f.MeasurementsFunc(ctx)
+}
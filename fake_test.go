@@ -0,0 +1,70 @@
+package airly
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFakeInstallationService(t *testing.T) {
+	fake := &FakeInstallationService{
+		ByIDFunc: func(ctx context.Context, id int64) (Installation, error) {
+			return mockInstallation, nil
+		},
+	}
+	var api InstallationAPI = fake
+
+	got, err := api.ByID(context.Background(), 9599)
+	if err != nil {
+		t.Errorf("ByID: %v", err)
+	}
+	if !reflect.DeepEqual(got, mockInstallation) {
+		t.Errorf("ByID returned %+v, want %+v", got, mockInstallation)
+	}
+
+	if _, err := api.Nearest(context.Background(), NewNearestInstallationOpts(100, 0)); err == nil {
+		t.Error("Nearest accepted invalid opts")
+	}
+
+	want := errors.New("boom")
+	fake.Err = want
+	if _, err := api.ByID(context.Background(), 1); err != want {
+		t.Errorf("ByID returned %v, want %v", err, want)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("recorded %d calls, want 3", len(calls))
+	}
+	if c := calls[2]; c.Method != "ByID" || !reflect.DeepEqual(c.Args, []interface{}{int64(1)}) {
+		t.Errorf("last call %+v, want ByID(1)", c)
+	}
+
+	fake.Reset()
+	if n := len(fake.Calls()); n != 0 {
+		t.Errorf("recorded %d calls after Reset, want 0", n)
+	}
+}
+
+func TestFakeMeasurementService(t *testing.T) {
+	fake := &FakeMeasurementService{
+		ForPointFunc: func(ctx context.Context, opts *ForPointMeasurementOpts) (Measurement, error) {
+			return mockMeasurement, nil
+		},
+	}
+	var api MeasurementAPI = fake
+
+	got, err := api.ForPoint(context.Background(), NewForPointMeasurementOpts(50.06, 19.94))
+	if err != nil {
+		t.Errorf("ForPoint: %v", err)
+	}
+	if !reflect.DeepEqual(got, mockMeasurement) {
+		t.Errorf("ForPoint returned %+v, want %+v", got, mockMeasurement)
+	}
+
+	got, err = api.ByID(context.Background(), NewByIDMeasurementOpts(1))
+	if err != nil || !reflect.DeepEqual(got, Measurement{}) {
+		t.Errorf("unprogrammed ByID returned %+v, %v, want zero value", got, err)
+	}
+}
@@ -0,0 +1,231 @@
+package forecast
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+// GroupBy selects the dimensions Tracker.Accuracy groups errors by,
+// besides the name. Errors of different values and indexes are never
+// combined, as they are in different units.
+type GroupBy int
+
+const (
+	// ByInstallation groups errors by the installation they were tracked for.
+	ByInstallation GroupBy = 1 << iota
+	// ByLeadTime groups errors by the lead time of the forecast.
+	ByLeadTime
+)
+
+// Accuracy holds error metrics of the forecasts of a group.
+// Dimensions the group is not split by are zero.
+type Accuracy struct {
+	InstallationID int64
+	// Name is the name of a value, e.g. "PM25", or of an index,
+	// e.g. "AIRLY_CAQI".
+	Name string
+	// LeadTime is the time between the forecast being issued and the end
+	// of the forecast hour, from 1h for the next hour.
+	LeadTime time.Duration
+
+	// Count is the number of forecasts matched with measurements.
+	Count int
+	// MAE is the mean absolute error, RMSE the root mean square error.
+	MAE  float64
+	RMSE float64
+	// Bias is the mean error, positive when forecasts are too high.
+	Bias float64
+	// LevelHitRate is the share of index forecasts with the level of the
+	// measurement, NaN for values.
+	LevelHitRate float64
+}
+
+// Tracker stores forecasts and matches them with the measurements of the
+// same hour received later to compute how accurate the forecasts are.
+// A Tracker is safe for concurrent use.
+type Tracker struct {
+	mu          sync.Mutex
+	predictions map[hourKey]map[time.Time]prediction
+	errors      map[groupKey]*errorSums
+}
+
+type hourKey struct {
+	installationID int64
+	name           string
+	from           time.Time
+}
+
+type groupKey struct {
+	installationID int64
+	name           string
+	leadTime       time.Duration
+}
+
+type prediction struct {
+	value float64
+	level string
+	index bool
+	lead  time.Duration
+}
+
+type errorSums struct {
+	n, levelN, levelHits   int
+	sum, sumAbs, sumSquare float64
+}
+
+// NewTracker creates an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		predictions: make(map[hourKey]map[time.Time]prediction),
+		errors:      make(map[groupKey]*errorSums),
+	}
+}
+
+// Track stores the forecast of m, fetched for the installation id, and
+// matches the stored forecasts with the History and Current of m. Every
+// forecast is matched once, so m can be tracked every time it is fetched.
+// Forecasts older than the History of m are dropped.
+func (t *Tracker) Track(id int64, m airly.Measurement) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	issued := m.Current.TillDateTime
+	for _, d := range m.Forecast {
+		lead := d.TillDateTime.Sub(issued)
+		eachValue(d, func(name string, p prediction) {
+			p.lead = lead
+			k := hourKey{installationID: id, name: name, from: d.FromDateTime}
+			if t.predictions[k] == nil {
+				t.predictions[k] = make(map[time.Time]prediction)
+			}
+			t.predictions[k][issued] = p
+		})
+	}
+
+	observed := append(append([]airly.Data(nil), m.History...), m.Current)
+	oldest := issued
+	for _, d := range observed {
+		if d.FromDateTime.IsZero() {
+			continue
+		}
+		if d.FromDateTime.Before(oldest) {
+			oldest = d.FromDateTime
+		}
+		eachValue(d, func(name string, actual prediction) {
+			k := hourKey{installationID: id, name: name, from: d.FromDateTime}
+			for _, p := range t.predictions[k] {
+				t.record(id, name, p, actual)
+			}
+			delete(t.predictions, k)
+		})
+	}
+
+	for k := range t.predictions {
+		if k.installationID == id && k.from.Before(oldest) {
+			delete(t.predictions, k)
+		}
+	}
+}
+
+func (t *Tracker) record(id int64, name string, p, actual prediction) {
+	k := groupKey{installationID: id, name: name, leadTime: p.lead}
+	s := t.errors[k]
+	if s == nil {
+		s = &errorSums{}
+		t.errors[k] = s
+	}
+	e := p.value - actual.value
+	s.n++
+	s.sum += e
+	s.sumAbs += math.Abs(e)
+	s.sumSquare += e * e
+	if p.index && p.level != "" && actual.level != "" {
+		s.levelN++
+		if p.level == actual.level {
+			s.levelHits++
+		}
+	}
+}
+
+// eachValue calls f for every value and index of d.
+func eachValue(d airly.Data, f func(name string, p prediction)) {
+	for _, v := range d.Values {
+		f(v.Name, prediction{value: v.Value})
+	}
+	for _, idx := range d.Indexes {
+		f(idx.Name, prediction{value: idx.Value, level: idx.Level, index: true})
+	}
+}
+
+// Pending returns the number of stored forecast values not matched yet.
+func (t *Tracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for _, issues := range t.predictions {
+		n += len(issues)
+	}
+	return n
+}
+
+// Accuracy returns the error metrics of the matched forecasts grouped
+// by name and the dimensions in by, e.g. ByLeadTime, sorted by
+// installation, name and lead time.
+func (t *Tracker) Accuracy(by GroupBy) []Accuracy {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	groups := make(map[groupKey]*errorSums)
+	for k, s := range t.errors {
+		if by&ByInstallation == 0 {
+			k.installationID = 0
+		}
+		if by&ByLeadTime == 0 {
+			k.leadTime = 0
+		}
+		g := groups[k]
+		if g == nil {
+			g = &errorSums{}
+			groups[k] = g
+		}
+		g.n += s.n
+		g.levelN += s.levelN
+		g.levelHits += s.levelHits
+		g.sum += s.sum
+		g.sumAbs += s.sumAbs
+		g.sumSquare += s.sumSquare
+	}
+
+	out := make([]Accuracy, 0, len(groups))
+	for k, s := range groups {
+		a := Accuracy{
+			InstallationID: k.installationID,
+			Name:           k.name,
+			LeadTime:       k.leadTime,
+			Count:          s.n,
+			MAE:            s.sumAbs / float64(s.n),
+			RMSE:           math.Sqrt(s.sumSquare / float64(s.n)),
+			Bias:           s.sum / float64(s.n),
+			LevelHitRate:   math.NaN(),
+		}
+		if s.levelN > 0 {
+			a.LevelHitRate = float64(s.levelHits) / float64(s.levelN)
+		}
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.InstallationID != b.InstallationID {
+			return a.InstallationID < b.InstallationID
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.LeadTime < b.LeadTime
+	})
+	return out
+}
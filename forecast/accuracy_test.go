@@ -0,0 +1,100 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+var epoch = time.Date(2020, 5, 7, 15, 0, 0, 0, time.UTC)
+
+func hour(h int, pm25 float64, level string) airly.Data {
+	from := epoch.Add(time.Duration(h-1) * time.Hour)
+	return airly.Data{
+		FromDateTime: from,
+		TillDateTime: from.Add(time.Hour),
+		Values:       []airly.Value{{Name: "PM25", Value: pm25}},
+		Indexes:      []airly.Index{{Name: "AIRLY_CAQI", Value: pm25 * 2, Level: level}},
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker()
+	tr.Track(1, airly.Measurement{
+		Current:  hour(0, 10, "VERY_LOW"),
+		Forecast: []airly.Data{hour(1, 10, "VERY_LOW"), hour(2, 20, "LOW"), hour(3, 30, "MEDIUM")},
+	})
+	tr.Track(1, airly.Measurement{
+		Current:  hour(1, 12, "VERY_LOW"),
+		History:  []airly.Data{hour(0, 10, "VERY_LOW")},
+		Forecast: []airly.Data{hour(2, 26, "MEDIUM"), hour(3, 30, "MEDIUM")},
+	})
+	// Tracking the same measurement again does not count it twice.
+	tr.Track(1, airly.Measurement{
+		Current: hour(2, 24, "LOW"),
+		History: []airly.Data{hour(0, 10, "VERY_LOW"), hour(1, 12, "VERY_LOW")},
+	})
+	tr.Track(1, airly.Measurement{
+		Current: hour(2, 24, "LOW"),
+		History: []airly.Data{hour(0, 10, "VERY_LOW"), hour(1, 12, "VERY_LOW")},
+	})
+
+	// PM25 errors: hour 1 lead 1h: -2; hour 2 lead 2h: -4, lead 1h: +2.
+	got := tr.Accuracy(ByLeadTime)
+	want := []Accuracy{
+		{Name: "AIRLY_CAQI", LeadTime: time.Hour, Count: 2, MAE: 4, RMSE: 4, Bias: 0, LevelHitRate: 0.5},
+		{Name: "AIRLY_CAQI", LeadTime: 2 * time.Hour, Count: 1, MAE: 8, RMSE: 8, Bias: -8, LevelHitRate: 1},
+		{Name: "PM25", LeadTime: time.Hour, Count: 2, MAE: 2, RMSE: 2, Bias: 0},
+		{Name: "PM25", LeadTime: 2 * time.Hour, Count: 1, MAE: 4, RMSE: 4, Bias: -4},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Accuracy returned %+v, want %+v", got, want)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Name != w.Name || g.LeadTime != w.LeadTime || g.Count != w.Count || g.InstallationID != 0 ||
+			!near(g.MAE, w.MAE) || !near(g.RMSE, w.RMSE) || !near(g.Bias, w.Bias) {
+			t.Errorf("Accuracy[%d] = %+v, want %+v", i, g, w)
+		}
+		if w.Name == "PM25" && !math.IsNaN(g.LevelHitRate) {
+			t.Errorf("Accuracy[%d].LevelHitRate = %v, want NaN", i, g.LevelHitRate)
+		}
+		if w.Name == "AIRLY_CAQI" && !near(g.LevelHitRate, w.LevelHitRate) {
+			t.Errorf("Accuracy[%d].LevelHitRate = %v, want %v", i, g.LevelHitRate, w.LevelHitRate)
+		}
+	}
+
+	pm25 := tr.Accuracy(ByInstallation)[1]
+	if pm25.InstallationID != 1 || pm25.Name != "PM25" || pm25.Count != 3 || !near(pm25.RMSE, math.Sqrt(8)) {
+		t.Errorf("Accuracy by installation = %+v", pm25)
+	}
+
+	// Values and indexes are never combined.
+	if got := tr.Accuracy(0); len(got) != 2 || got[0].Name != "AIRLY_CAQI" || got[1].Name != "PM25" {
+		t.Errorf("Accuracy = %+v, want it grouped by name", got)
+	}
+
+	// Both forecasts of hour 3 are still pending.
+	if got := tr.Pending(); got != 4 {
+		t.Errorf("Pending returned %d, want 4", got)
+	}
+}
+
+func TestTracker_dropsUnmatchedForecasts(t *testing.T) {
+	tr := NewTracker()
+	tr.Track(1, airly.Measurement{Current: hour(0, 10, ""), Forecast: []airly.Data{hour(1, 10, "")}})
+	tr.Track(1, airly.Measurement{Current: hour(5, 10, ""), History: []airly.Data{hour(3, 10, ""), hour(4, 10, "")}})
+
+	if got := tr.Pending(); got != 0 {
+		t.Errorf("Pending returned %d, want 0", got)
+	}
+	if got := tr.Accuracy(0); len(got) != 0 {
+		t.Errorf("Accuracy returned %+v, want none", got)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
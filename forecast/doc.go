@@ -0,0 +1,3 @@
+// Package forecast evaluates Airly forecasts against the measurements
+// that followed them, and forecasts locally when Airly does not.
+package forecast
@@ -0,0 +1,133 @@
+package forecast
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+	"github.com/lsjurczak/go-airly/series"
+)
+
+const defaultHours = 24
+
+// Options configures Local and Fill.
+type Options struct {
+	// Model predicts every value, SeasonalNaive if nil.
+	Model Model
+	// Hours is the number of forecast hours, 24 if zero.
+	Hours int
+	// Names are the values forecasted, PM25 and PM10 like the Airly
+	// forecast if empty. Values other than TEMPERATURE are not negative.
+	Names []string
+}
+
+// Local forecasts the hours following history, e.g. an archive of
+// Measurement.History, with opts.Model. Missing hours of history are
+// interpolated. The returned data has the shape of Measurement.Forecast
+// without indexes and standards, and its LocalModel is the model name.
+func Local(history []airly.Data, opts Options) ([]airly.Data, error) {
+	if opts.Model == nil {
+		opts.Model = SeasonalNaive{}
+	}
+	if opts.Hours <= 0 {
+		opts.Hours = defaultHours
+	}
+	if len(opts.Names) == 0 {
+		opts.Names = []string{"PM25", "PM10"}
+	}
+	history = merge(history)
+	if len(history) == 0 {
+		return nil, ErrInsufficientHistory
+	}
+	end := history[len(history)-1].TillDateTime
+
+	out := make([]airly.Data, opts.Hours)
+	for h := range out {
+		from := end.Add(time.Duration(h) * time.Hour)
+		out[h] = airly.Data{
+			FromDateTime: from,
+			TillDateTime: from.Add(time.Hour),
+			Values:       []airly.Value{},
+			Indexes:      []airly.Index{},
+			Standards:    []airly.Standard{},
+			LocalModel:   opts.Model.Name(),
+		}
+	}
+
+	for _, name := range opts.Names {
+		s := series.Extract(history, name)
+		if len(s) == 0 {
+			continue
+		}
+		values := hourly(s)
+		// The value may be missing in the last hours of history.
+		skip := int(end.Sub(s[len(s)-1].Till) / time.Hour)
+		predicted, err := opts.Model.Predict(values, skip+opts.Hours)
+		if err != nil {
+			return nil, err
+		}
+		for h := range out {
+			v := predicted[skip+h]
+			if name != "TEMPERATURE" {
+				v = math.Max(v, 0)
+			}
+			out[h].Values = append(out[h].Values, airly.Value{Name: name, Value: math.Round(v*100) / 100})
+		}
+	}
+	return out, nil
+}
+
+// Fill sets m.Forecast to a Local forecast from archive, m.History and
+// m.Current when Airly returned no forecast, and reports whether it did.
+func Fill(m *airly.Measurement, archive []airly.Data, opts Options) (bool, error) {
+	if len(m.Forecast) > 0 {
+		return false, nil
+	}
+	history := append(append(append([]airly.Data(nil), archive...), m.History...), m.Current)
+	forecast, err := Local(history, opts)
+	if err != nil {
+		return false, err
+	}
+	m.Forecast = forecast
+	return true, nil
+}
+
+// merge returns data sorted by FromDateTime without duplicate hours,
+// keeping the last of them.
+func merge(data []airly.Data) []airly.Data {
+	byFrom := make(map[time.Time]airly.Data, len(data))
+	for _, d := range data {
+		if !d.FromDateTime.IsZero() {
+			byFrom[d.FromDateTime] = d
+		}
+	}
+	out := make([]airly.Data, 0, len(byFrom))
+	for _, d := range byFrom {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].FromDateTime.Before(out[j].FromDateTime)
+	})
+	return out
+}
+
+// hourly returns the values of s for every hour from its first till its
+// last point, interpolating missing hours linearly.
+func hourly(s series.Series) []float64 {
+	n := int(s[len(s)-1].From.Sub(s[0].From)/time.Hour) + 1
+	values := make([]float64, n)
+	prev := 0
+	for i, p := range s {
+		at := int(p.From.Sub(s[0].From) / time.Hour)
+		values[at] = p.Value
+		if i > 0 {
+			for h := prev + 1; h < at; h++ {
+				f := float64(h-prev) / float64(at-prev)
+				values[h] = values[prev] + (p.Value-values[prev])*f
+			}
+		}
+		prev = at
+	}
+	return values
+}
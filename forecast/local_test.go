@@ -0,0 +1,76 @@
+package forecast
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+	"github.com/lsjurczak/go-airly/airlytest"
+)
+
+func TestFill(t *testing.T) {
+	m := airlytest.DefaultDataset().Measurements[9599]
+	want := m.Forecast
+	m.Forecast = nil
+
+	filled, err := Fill(&m, nil, Options{})
+	if err != nil {
+		t.Fatalf("Fill returned error: %v", err)
+	}
+	if !filled {
+		t.Fatal("Fill did not fill the forecast")
+	}
+	// The default dataset repeats every day, like the seasonal naive model.
+	if len(m.Forecast) != len(want) {
+		t.Fatalf("Fill returned %d hours, want %d", len(m.Forecast), len(want))
+	}
+	for i, got := range m.Forecast {
+		if got.LocalModel != "seasonal-naive" {
+			t.Errorf("hour %d: LocalModel = %q, want seasonal-naive", i, got.LocalModel)
+		}
+		if !got.FromDateTime.Equal(want[i].FromDateTime) || !reflect.DeepEqual(got.Values, want[i].Values) {
+			t.Errorf("hour %d: %v %+v, want %v %+v", i, got.FromDateTime, got.Values, want[i].FromDateTime, want[i].Values)
+		}
+	}
+
+	if filled, _ := Fill(&m, nil, Options{Model: AR{}}); filled {
+		t.Error("Fill replaced an existing forecast")
+	}
+}
+
+func TestLocal_missingHours(t *testing.T) {
+	from := time.Date(2020, 5, 7, 0, 0, 0, 0, time.UTC)
+	hour := func(h int, values ...airly.Value) airly.Data {
+		return airly.Data{
+			FromDateTime: from.Add(time.Duration(h) * time.Hour),
+			TillDateTime: from.Add(time.Duration(h+1) * time.Hour),
+			Values:       values,
+		}
+	}
+	history := []airly.Data{
+		hour(0, airly.Value{Name: "PM25", Value: 10}),
+		hour(3, airly.Value{Name: "PM25", Value: 40}),
+		// PM25 is missing in the last hour.
+		hour(4, airly.Value{Name: "TEMPERATURE", Value: -3}),
+	}
+
+	got, err := Local(history, Options{Model: SeasonalNaive{Period: 4}, Hours: 2, Names: []string{"PM25"}})
+	if err != nil {
+		t.Fatalf("Local returned error: %v", err)
+	}
+	// PM25 is 10, 20, 30, 40 with interpolated hours, the forecast skips
+	// the hour missing at the end.
+	want := [][]airly.Value{
+		{{Name: "PM25", Value: 20}},
+		{{Name: "PM25", Value: 30}},
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i].Values, want[i]) {
+			t.Errorf("hour %d: %+v, want %+v", i, got[i].Values, want[i])
+		}
+	}
+	if !got[0].FromDateTime.Equal(from.Add(5 * time.Hour)) {
+		t.Errorf("forecast starts at %v, want %v", got[0].FromDateTime, from.Add(5*time.Hour))
+	}
+}
@@ -0,0 +1,221 @@
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	defaultPeriod  = 24
+	defaultARorder = 3
+
+	defaultAlpha = 0.5
+	defaultBeta  = 0.1
+	defaultGamma = 0.3
+)
+
+// ErrInsufficientHistory is returned when the history is too short
+// for the model.
+var ErrInsufficientHistory = errors.New("forecast: insufficient history")
+
+// Model predicts the next values of an hourly time series.
+type Model interface {
+	// Name identifies the model in Data.LocalModel.
+	Name() string
+	// Predict returns the horizon values following values.
+	Predict(values []float64, horizon int) ([]float64, error)
+}
+
+// SeasonalNaive repeats the values of the last period.
+type SeasonalNaive struct {
+	// Period is the number of hours in a season, 24 if zero.
+	Period int
+}
+
+// Name returns "seasonal-naive".
+func (m SeasonalNaive) Name() string {
+	return "seasonal-naive"
+}
+
+// Predict implements Model.
+func (m SeasonalNaive) Predict(values []float64, horizon int) ([]float64, error) {
+	period := orDefault(m.Period, defaultPeriod)
+	if len(values) < period {
+		return nil, ErrInsufficientHistory
+	}
+	last := values[len(values)-period:]
+	out := make([]float64, horizon)
+	for h := range out {
+		out[h] = last[h%period]
+	}
+	return out, nil
+}
+
+// HoltWinters is the additive Holt-Winters triple exponential smoothing.
+// Zero smoothing factors take the defaults of 0.5, 0.1 and 0.3.
+type HoltWinters struct {
+	// Alpha, Beta and Gamma smooth the level, trend and season.
+	Alpha, Beta, Gamma float64
+	// Period is the number of hours in a season, 24 if zero.
+	Period int
+}
+
+// Name returns "holt-winters".
+func (m HoltWinters) Name() string {
+	return "holt-winters"
+}
+
+// Predict implements Model. It needs at least two periods of values.
+func (m HoltWinters) Predict(values []float64, horizon int) ([]float64, error) {
+	period := orDefault(m.Period, defaultPeriod)
+	if len(values) < 2*period {
+		return nil, ErrInsufficientHistory
+	}
+	alpha := orDefaultFloat(m.Alpha, defaultAlpha)
+	beta := orDefaultFloat(m.Beta, defaultBeta)
+	gamma := orDefaultFloat(m.Gamma, defaultGamma)
+
+	// Initialize the components from the complete periods of values.
+	periods := len(values) / period
+	means := make([]float64, periods)
+	for k := range means {
+		means[k] = mean(values[k*period : (k+1)*period])
+	}
+	level := means[0]
+	trend := (means[periods-1] - means[0]) / float64((periods-1)*period)
+	season := make([]float64, period)
+	for i := range season {
+		for k, m := range means {
+			season[i] += values[k*period+i] - m - trend*(float64(i)-float64(period-1)/2)
+		}
+		season[i] /= float64(periods)
+	}
+
+	for t, y := range values {
+		s := season[t%period]
+		prev := level
+		level = alpha*(y-s) + (1-alpha)*(level+trend)
+		trend = beta*(level-prev) + (1-beta)*trend
+		season[t%period] = gamma*(y-level) + (1-gamma)*s
+	}
+
+	out := make([]float64, horizon)
+	for h := range out {
+		out[h] = level + float64(h+1)*trend + season[(len(values)+h)%period]
+	}
+	return out, nil
+}
+
+// AR is an autoregressive model fitted with least squares.
+type AR struct {
+	// Order is the number of previous values a value depends on,
+	// 3 if zero.
+	Order int
+}
+
+// Name returns "ar(p)" with the order of the model.
+func (m AR) Name() string {
+	return fmt.Sprintf("ar(%d)", orDefault(m.Order, defaultARorder))
+}
+
+// Predict implements Model. It needs more than twice Order values.
+func (m AR) Predict(values []float64, horizon int) ([]float64, error) {
+	p := orDefault(m.Order, defaultARorder)
+	if len(values) <= 2*p {
+		return nil, ErrInsufficientHistory
+	}
+	mu := mean(values)
+	x := make([]float64, len(values))
+	for i, v := range values {
+		x[i] = v - mu
+	}
+
+	// Normal equations of x[t] = sum(phi[i] * x[t-i-1]).
+	a := make([][]float64, p)
+	b := make([]float64, p)
+	for i := range a {
+		a[i] = make([]float64, p)
+	}
+	for t := p; t < len(x); t++ {
+		for i := 0; i < p; i++ {
+			b[i] += x[t] * x[t-i-1]
+			for j := 0; j < p; j++ {
+				a[i][j] += x[t-i-1] * x[t-j-1]
+			}
+		}
+	}
+	phi, err := solve(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	for h := 0; h < horizon; h++ {
+		var next float64
+		for i, c := range phi {
+			next += c * x[len(x)-i-1]
+		}
+		x = append(x, next)
+	}
+	out := make([]float64, horizon)
+	for h := range out {
+		out[h] = x[len(values)+h] + mu
+	}
+	return out, nil
+}
+
+// solve solves a*x = b with Gaussian elimination, modifying a and b.
+func solve(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, errors.New("forecast: constant history")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for r := col + 1; r < n; r++ {
+			f := a[r][col] / a[col][col]
+			for c := col; c < n; c++ {
+				a[r][c] -= f * a[col][c]
+			}
+			b[r] -= f * b[col]
+		}
+	}
+	x := make([]float64, n)
+	for r := n - 1; r >= 0; r-- {
+		sum := b[r]
+		for c := r + 1; c < n; c++ {
+			sum -= a[r][c] * x[c]
+		}
+		x[r] = sum / a[r][r]
+	}
+	return x, nil
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func orDefault(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+func orDefaultFloat(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,79 @@
+package forecast
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// seasonal returns n values of a daily cycle with a trend per hour.
+func seasonal(n int, trend float64) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = 20 + 10*math.Sin(2*math.Pi*float64(i)/24) + trend*float64(i)
+	}
+	return values
+}
+
+func TestSeasonalNaive(t *testing.T) {
+	got, err := SeasonalNaive{Period: 3}.Predict([]float64{9, 1, 2, 3}, 5)
+	if err != nil {
+		t.Fatalf("Predict returned error: %v", err)
+	}
+	want := []float64{1, 2, 3, 1, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Predict returned %v, want %v", got, want)
+		}
+	}
+}
+
+func TestHoltWinters(t *testing.T) {
+	values := seasonal(24*7+6, 0.1)
+	future := seasonal(24*8, 0.1)[len(values):]
+
+	got, err := HoltWinters{}.Predict(values, len(future))
+	if err != nil {
+		t.Fatalf("Predict returned error: %v", err)
+	}
+	for i := range future {
+		if math.Abs(got[i]-future[i]) > 1 {
+			t.Errorf("hour %d: predicted %.2f, want %.2f", i, got[i], future[i])
+		}
+	}
+}
+
+func TestAR(t *testing.T) {
+	// x[t] = 10 + 0.8 * (x[t-1] - 10) with a small disturbance.
+	values := []float64{30}
+	for i := 1; i < 200; i++ {
+		noise := 0.5 * math.Sin(float64(i)*1.7)
+		values = append(values, 10+0.8*(values[i-1]-10)+noise)
+	}
+
+	got, err := AR{Order: 1}.Predict(values, 50)
+	if err != nil {
+		t.Fatalf("Predict returned error: %v", err)
+	}
+	if last := got[len(got)-1]; math.Abs(last-mean(values)) > 0.5 {
+		t.Errorf("long term prediction %.2f, want the mean %.2f", last, mean(values))
+	}
+	if got := (AR{}).Name(); got != "ar(3)" {
+		t.Errorf("Name returned %q, want ar(3)", got)
+	}
+}
+
+func TestModels_insufficientHistory(t *testing.T) {
+	for _, m := range []Model{SeasonalNaive{}, HoltWinters{}, AR{}} {
+		if _, err := m.Predict(seasonal(6, 0), 1); !errors.Is(err, ErrInsufficientHistory) {
+			t.Errorf("%s: Predict returned %v, want ErrInsufficientHistory", m.Name(), err)
+		}
+	}
+}
+
+func TestAR_constantHistory(t *testing.T) {
+	values := make([]float64, 30)
+	if _, err := (AR{}).Predict(values, 1); err == nil {
+		t.Error("Predict returned nil error for a constant history")
+	}
+}
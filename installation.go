@@ -1,9 +1,19 @@
 package airly
 
 import (
+	"context"
 	"fmt"
 )
 
+// InstallationAPI is the set of installation operations, satisfied by
+// InstallationService and FakeInstallationService.
+type InstallationAPI interface {
+	ByID(ctx context.Context, id int64) (Installation, error)
+	Nearest(ctx context.Context, opts *NearestInstallationOpts) ([]Installation, error)
+}
+
+var _ InstallationAPI = (*InstallationService)(nil)
+
 // InstallationService is used to installation operations.
 // https://developer.airly.eu/docs#endpoints.installations
 type InstallationService struct {
@@ -48,33 +58,55 @@ type Installation struct {
 
 // ByID returns single installation metadata given by installationID.
 // https://developer.airly.eu/docs#endpoints.installations.getbyid
-func (s *InstallationService) ByID(id int64) (Installation, error) {
+func (s *InstallationService) ByID(ctx context.Context, id int64) (Installation, error) {
+	v, _, err := s.ByIDWithResponse(ctx, id)
+	return v, err
+}
+
+// ByIDWithResponse is like ByID, but also returns metadata of the API response.
+func (s *InstallationService) ByIDWithResponse(ctx context.Context, id int64) (Installation, *Response, error) {
 	var installation Installation
 	u := fmt.Sprintf("installations/%d", id)
-	err := s.client.get(u, nil, &installation)
+	resp, err := s.client.get(ctx, Operation{Name: "Installation.ByID", InstallationID: id}, u, nil, &installation)
 	if err != nil {
-		return Installation{}, err
+		return Installation{}, resp, err
 	}
-	return installation, nil
+	return installation, resp, nil
 }
 
-type nearestInstallationOpts struct {
-	*urlQuery
+// NearestInstallationOpts holds params of the nearest installation query.
+type NearestInstallationOpts struct {
+	urlQuery
 }
 
 // NewNearestInstallationOpts is an opts builder for the nearest installation query.
-func NewNearestInstallationOpts(lat, lng float64) *nearestInstallationOpts {
-	return &nearestInstallationOpts{
-		NewURLQuery().setLocation(lat, lng),
+// Out of range coordinates are reported by Validate.
+func NewNearestInstallationOpts(lat, lng float64) *NearestInstallationOpts {
+	return &NearestInstallationOpts{
+		*NewURLQuery().setLocation(lat, lng),
 	}
 }
 
-func (q *nearestInstallationOpts) MaxDistance(km float64) *nearestInstallationOpts {
+// Clone returns a copy of q that can be modified independently.
+func (q *NearestInstallationOpts) Clone() *NearestInstallationOpts {
+	return &NearestInstallationOpts{*q.clone()}
+}
+
+// Validate reports all problems found in the query params,
+// including missing ones in a zero value.
+func (q *NearestInstallationOpts) Validate() error {
+	return q.validate("lat", "lng")
+}
+
+// MaxDistance limits results to installations within km kilometers.
+func (q *NearestInstallationOpts) MaxDistance(km float64) *NearestInstallationOpts {
 	q.setMaxDistance(km)
 	return q
 }
 
-func (q *nearestInstallationOpts) MaxResults(limit float64) *nearestInstallationOpts {
+// MaxResults limits the number of returned installations,
+// UnlimitedResults returns all of them.
+func (q *NearestInstallationOpts) MaxResults(limit int) *NearestInstallationOpts {
 	q.setMaxResults(limit)
 	return q
 }
@@ -82,11 +114,20 @@ func (q *nearestInstallationOpts) MaxResults(limit float64) *nearestInstallation
 // Nearest returns list of installations closest to a given point,
 // sorted by distance to that point.
 // https://developer.airly.eu/docs#endpoints.installations.nearest
-func (s *InstallationService) Nearest(opts *nearestInstallationOpts) ([]Installation, error) {
+func (s *InstallationService) Nearest(ctx context.Context, opts *NearestInstallationOpts) ([]Installation, error) {
+	v, _, err := s.NearestWithResponse(ctx, opts)
+	return v, err
+}
+
+// NearestWithResponse is like Nearest, but also returns metadata of the API response.
+func (s *InstallationService) NearestWithResponse(ctx context.Context, opts *NearestInstallationOpts) ([]Installation, *Response, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, nil, localize(err, s.client.requestLanguage(ctx))
+	}
 	var installations []Installation
-	err := s.client.get("installations/nearest", opts.opts, &installations)
+	resp, err := s.client.get(ctx, Operation{Name: "Installation.Nearest"}, "installations/nearest", opts.opts, &installations)
 	if err != nil {
-		return nil, err
+		return nil, resp, err
 	}
-	return installations, nil
+	return installations, resp, nil
 }
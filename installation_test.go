@@ -1,6 +1,7 @@
 package airly
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -16,7 +17,7 @@ func TestInstallationService_ByID(t *testing.T) {
 		fmt.Fprint(w, mockInstallationResponse)
 	})
 
-	got, err := client.Installation.ByID(6600)
+	got, err := client.Installation.ByID(context.Background(), 6600)
 	if err != nil {
 		t.Errorf("Installation.ByID: %v", err)
 	}
@@ -36,7 +37,7 @@ func TestInstallationService_Nearest(t *testing.T) {
 		fmt.Fprint(w, mockListInstallationResponse)
 	})
 
-	got, err := client.Installation.Nearest(opt)
+	got, err := client.Installation.Nearest(context.Background(), opt)
 	if err != nil {
 		t.Errorf("Installation.Nearest: %v", err)
 	}
@@ -45,6 +46,47 @@ func TestInstallationService_Nearest(t *testing.T) {
 	}
 }
 
+func TestInstallationService_Nearest_invalidOpts(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/installations/nearest", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent for invalid opts")
+	})
+
+	opt := NewNearestInstallationOpts(200, 19.940984).MaxResults(0)
+	_, err := client.Installation.Nearest(context.Background(), opt)
+	if _, ok := err.(ValidationError); !ok {
+		t.Errorf("Installation.Nearest returned %v, want ValidationError", err)
+	}
+}
+
+func TestInstallationService_Nearest_zeroOpts(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/installations/nearest", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent for zero opts")
+	})
+
+	var opt NearestInstallationOpts
+	_, err := client.Installation.Nearest(context.Background(), opt.Clone().MaxResults(1))
+	verr, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("Installation.Nearest returned %v, want ValidationError", err)
+	}
+	var params []string
+	for _, p := range verr.Params {
+		params = append(params, p.Parameter)
+	}
+	if want := []string{"lat", "lng"}; !reflect.DeepEqual(params, want) {
+		t.Errorf("Installation.Nearest reported %v, want %v", params, want)
+	}
+	if err := opt.Validate(); err == nil {
+		t.Error("Validate returned nil error for zero opts")
+	}
+}
+
 var (
 	mockInstallationResponse = `
 	{
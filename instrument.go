@@ -0,0 +1,70 @@
+package airly
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Operation describes a single service method call.
+type Operation struct {
+	// Name is the service method, e.g. "Measurement.ByID".
+	Name string
+	// InstallationID is zero when the call is not about an installation.
+	InstallationID int64
+	// Lat and Lng are set when HasLocation is true.
+	HasLocation bool
+	Lat, Lng    float64
+	// IndexType is empty unless set in the query.
+	IndexType string
+}
+
+func (op Operation) withParams(params url.Values) Operation {
+	if id, err := strconv.ParseInt(params.Get("installationId"), 10, 64); err == nil {
+		op.InstallationID = id
+	}
+	lat, latErr := strconv.ParseFloat(params.Get("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(params.Get("lng"), 64)
+	if latErr == nil && lngErr == nil {
+		op.HasLocation, op.Lat, op.Lng = true, lat, lng
+	}
+	op.IndexType = params.Get("indexType")
+	return op
+}
+
+// OperationResult describes the outcome of an Operation.
+type OperationResult struct {
+	// StatusCode is zero when no response was received,
+	// e.g. on network errors or when the result came from the cache.
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+	// RateLimitRemaining is the remaining daily quota of the api key,
+	// -1 when the response did not include it.
+	RateLimitRemaining int
+}
+
+// Instrumentation observes service method calls, e.g. to trace them
+// and record metrics. An OpenTelemetry implementation is provided by
+// the github.com/lsjurczak/go-airly/otelairly module.
+type Instrumentation interface {
+	// StartOperation is called before op sends its request. The returned
+	// context is used for the request and end is called with the result.
+	StartOperation(ctx context.Context, op Operation) (_ context.Context, end func(OperationResult))
+}
+
+// Instrument sets the instrumentation observing service method calls.
+func (c *Client) Instrument(i Instrumentation) *Client {
+	c.instrumentation = i
+	return c
+}
+
+func operationResult(resp *Response, err error, d time.Duration) OperationResult {
+	res := OperationResult{Duration: d, Err: err, RateLimitRemaining: -1}
+	if resp != nil && !resp.Cached {
+		res.StatusCode = resp.StatusCode
+		res.RateLimitRemaining = resp.RateLimits.Day.Remaining
+	}
+	return res
+}
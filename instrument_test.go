@@ -0,0 +1,79 @@
+package airly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type recordedOperation struct {
+	op     Operation
+	result OperationResult
+}
+
+type recordingInstrumentation struct {
+	ops []recordedOperation
+}
+
+type ctxKey struct{}
+
+func (r *recordingInstrumentation) StartOperation(ctx context.Context, op Operation) (context.Context, func(OperationResult)) {
+	r.ops = append(r.ops, recordedOperation{op: op})
+	i := len(r.ops) - 1
+	return context.WithValue(ctx, ctxKey{}, op.Name), func(res OperationResult) {
+		r.ops[i].result = res
+	}
+}
+
+func TestClient_Instrument(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	var inst recordingInstrumentation
+	client.Instrument(&inst)
+	client.Use(func(next HTTPDoer) HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if got := req.Context().Value(ctxKey{}); got == nil {
+				t.Error("request context does not come from StartOperation")
+			}
+			return next.Do(req)
+		})
+	})
+
+	mux.HandleFunc("/measurements/nearest", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining-day", "41")
+		fmt.Fprint(w, mockMeasurementResponse)
+	})
+	mux.HandleFunc("/installations/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errorCode":"INSTALLATION_NOT_FOUND","message":"Not found"}`)
+	})
+
+	opt := NewNearestMeasurementOpts(50.06, 19.94).IndexType(PIJP)
+	if _, err := client.Measurement.Nearest(context.Background(), opt); err != nil {
+		t.Fatalf("Measurement.Nearest: %v", err)
+	}
+	_, notFound := client.Installation.ByID(context.Background(), 1)
+
+	if len(inst.ops) != 2 {
+		t.Fatalf("recorded %d operations, want 2", len(inst.ops))
+	}
+
+	wantOp := Operation{Name: "Measurement.Nearest", HasLocation: true, Lat: 50.06, Lng: 19.94, IndexType: "PIJP"}
+	if got := inst.ops[0].op; !reflect.DeepEqual(got, wantOp) {
+		t.Errorf("operation %+v, want %+v", got, wantOp)
+	}
+	if res := inst.ops[0].result; res.StatusCode != http.StatusOK || res.Err != nil || res.RateLimitRemaining != 41 {
+		t.Errorf("result %+v", res)
+	}
+
+	wantOp = Operation{Name: "Installation.ByID", InstallationID: 1}
+	if got := inst.ops[1].op; !reflect.DeepEqual(got, wantOp) {
+		t.Errorf("operation %+v, want %+v", got, wantOp)
+	}
+	if res := inst.ops[1].result; res.StatusCode != http.StatusNotFound || !reflect.DeepEqual(res.Err, notFound) || res.RateLimitRemaining != -1 {
+		t.Errorf("result %+v", res)
+	}
+}
@@ -0,0 +1,236 @@
+package airly
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimitCooldown is how long a key that exceeded its per minute limit
+// is skipped.
+const rateLimitCooldown = time.Minute
+
+// KeyStrategy selects the key of a KeyPool used for the next request.
+type KeyStrategy int
+
+const (
+	// RoundRobin uses the keys in turn.
+	RoundRobin KeyStrategy = iota
+	// LeastUsed uses the key that sent the fewest requests.
+	LeastUsed
+	// Failover uses the first key until it is rate limited or rejected,
+	// then the next one.
+	Failover
+)
+
+// KeyUsage reports the usage of a single key of a KeyPool.
+type KeyUsage struct {
+	// Key is the api key with all but its last 4 characters masked.
+	Key string
+	// Requests is the number of requests sent with the key.
+	Requests int
+	// RateLimited is the number of 429 Too Many Requests responses.
+	RateLimited int
+	// Unauthorized reports whether the API rejected the key with
+	// 401 Unauthorized, it is not used since.
+	Unauthorized bool
+	// RateLimits are the limits of the key from its last response.
+	RateLimits RateLimits
+	LastUsed   time.Time
+	// AvailableAt is the time the key is used again after it was
+	// rate limited or its daily quota was used up.
+	AvailableAt time.Time
+}
+
+// KeyPool spreads requests over several api keys. Keys that are rate
+// limited or rejected are skipped, and a request that fails with 429
+// or 401 is retried with another key. A key whose daily quota is used up
+// is skipped until the next midnight UTC, a key that exceeded its per
+// minute limit for a minute.
+//
+// When no key is available, the one available the soonest is used, so that
+// errors still come from the API. A KeyPool is safe for concurrent use.
+type KeyPool struct {
+	strategy KeyStrategy
+
+	mu    sync.Mutex
+	keys  []string
+	usage []KeyUsage
+	next  int
+}
+
+// NewKeyPool creates a KeyPool of keys using strategy.
+func NewKeyPool(strategy KeyStrategy, keys ...string) (*KeyPool, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("missing api key")
+	}
+	p := &KeyPool{
+		strategy: strategy,
+		keys:     make([]string, len(keys)),
+		usage:    make([]KeyUsage, len(keys)),
+	}
+	for i, k := range keys {
+		if k == "" {
+			return nil, errors.New("missing api key")
+		}
+		p.keys[i] = k
+		p.usage[i] = KeyUsage{
+			Key:        maskKey(k),
+			RateLimits: RateLimits{Day: Rate{-1, -1}, Minute: Rate{-1, -1}},
+		}
+	}
+	return p, nil
+}
+
+// KeyPool makes the client send requests with the keys of pool instead of
+// its own api key. Pass a nil pool to use the client's key again.
+func (c *Client) KeyPool(pool *KeyPool) *Client {
+	c.keys = pool
+	return c
+}
+
+// Usage returns the usage of every key in the order they were given.
+func (p *KeyPool) Usage() []KeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]KeyUsage(nil), p.usage...)
+}
+
+// Len returns the number of keys.
+func (p *KeyPool) Len() int {
+	return len(p.keys)
+}
+
+// acquire returns the index of the key for the next request,
+// skipping the keys already tried.
+func (p *KeyPool) acquire(tried map[int]bool, now time.Time) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	available := func(i int) bool {
+		u := p.usage[i]
+		return !tried[i] && !u.Unauthorized && !now.Before(u.AvailableAt)
+	}
+	n := len(p.keys)
+	best := -1
+	switch p.strategy {
+	case LeastUsed:
+		for i := 0; i < n; i++ {
+			if available(i) && (best < 0 || p.usage[i].Requests < p.usage[best].Requests) {
+				best = i
+			}
+		}
+	case Failover:
+		for i := 0; i < n && best < 0; i++ {
+			if available(i) {
+				best = i
+			}
+		}
+	default:
+		for j := 0; j < n && best < 0; j++ {
+			if i := (p.next + j) % n; available(i) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			p.next = (best + 1) % n
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+
+	// No key is available, use the one that is available the soonest,
+	// preferring keys that were not tried or rejected.
+	for i := 0; i < n; i++ {
+		if best < 0 || p.worse(best, i, tried) {
+			best = i
+		}
+	}
+	return best
+}
+
+// worse reports whether key i is a worse fallback than key j.
+func (p *KeyPool) worse(i, j int, tried map[int]bool) bool {
+	ui, uj := p.usage[i], p.usage[j]
+	if tried[i] != tried[j] {
+		return tried[i]
+	}
+	if ui.Unauthorized != uj.Unauthorized {
+		return ui.Unauthorized
+	}
+	return uj.AvailableAt.Before(ui.AvailableAt)
+}
+
+// record updates the usage of key i with the outcome of a request.
+func (p *KeyPool) record(i int, resp *Response, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	u := &p.usage[i]
+	u.Requests++
+	u.LastUsed = now
+	if resp == nil {
+		return
+	}
+	limits := resp.RateLimits
+	if limits.Day.Limit >= 0 || limits.Day.Remaining >= 0 {
+		u.RateLimits.Day = limits.Day
+	}
+	if limits.Minute.Limit >= 0 || limits.Minute.Remaining >= 0 {
+		u.RateLimits.Minute = limits.Minute
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		u.Unauthorized = true
+	case limits.Day.Remaining == 0:
+		u.AvailableAt = nextMidnightUTC(now)
+	case resp.StatusCode == http.StatusTooManyRequests || limits.Minute.Remaining == 0:
+		u.AvailableAt = now.Add(rateLimitCooldown)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		u.RateLimited++
+	}
+}
+
+func nextMidnightUTC(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+func maskKey(key string) string {
+	const visible = 4
+	if len(key) <= visible {
+		return "****"
+	}
+	return "****" + key[len(key)-visible:]
+}
+
+// doWithKeys sends req with the keys of the pool, retrying with another key
+// when the API responds with 429 Too Many Requests or 401 Unauthorized.
+func (c *Client) doWithKeys(req *http.Request) ([]byte, *Response, error) {
+	var (
+		body []byte
+		resp *Response
+		err  error
+	)
+	// Every key is tried at most once, acquire prefers keys not tried yet.
+	tried := make(map[int]bool, c.keys.Len())
+	for attempt := 0; attempt < c.keys.Len(); attempt++ {
+		i := c.keys.acquire(tried, c.now())
+		tried[i] = true
+
+		keyed := req.Clone(req.Context())
+		keyed.Header.Set("apiKey", c.keys.keys[i])
+		body, resp, err = c.send(keyed)
+		c.keys.record(i, resp, c.now())
+		if resp == nil || resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusUnauthorized {
+			break
+		}
+		if req.Context().Err() != nil {
+			break
+		}
+	}
+	return body, resp, err
+}
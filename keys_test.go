@@ -0,0 +1,164 @@
+package airly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestKeyPool(t *testing.T, strategy KeyStrategy, keys ...string) *KeyPool {
+	t.Helper()
+	p, err := NewKeyPool(strategy, keys...)
+	if err != nil {
+		t.Fatalf("NewKeyPool: %v", err)
+	}
+	return p
+}
+
+func TestKeyPool_strategies(t *testing.T) {
+	tests := []struct {
+		strategy KeyStrategy
+		want     []string
+	}{
+		{RoundRobin, []string{"key-a", "key-b", "key-c", "key-a"}},
+		{LeastUsed, []string{"key-a", "key-b", "key-c", "key-a"}},
+		{Failover, []string{"key-a", "key-a", "key-a", "key-a"}},
+	}
+	for _, tt := range tests {
+		client, mux, teardown := setup()
+		var got []string
+		mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+			got = append(got, r.Header.Get("apiKey"))
+			fmt.Fprint(w, mockIndexesResponse)
+		})
+		client.KeyPool(newTestKeyPool(t, tt.strategy, "key-a", "key-b", "key-c"))
+
+		for range tt.want {
+			if _, err := client.Meta.Indexes(context.Background()); err != nil {
+				t.Fatalf("Meta.Indexes returned error: %v", err)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("strategy %d used keys %v, want %v", tt.strategy, got, tt.want)
+		}
+		teardown()
+	}
+}
+
+func TestKeyPool_failover(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	now := time.Date(2020, 5, 7, 15, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+	pool := newTestKeyPool(t, Failover, "key-limited", "key-revoked", "key-ok")
+	client.KeyPool(pool)
+
+	var got []string
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("apiKey")
+		got = append(got, key)
+		switch key {
+		case "key-limited":
+			w.Header().Set("X-RateLimit-Limit-day", "100")
+			w.Header().Set("X-RateLimit-Remaining-day", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"errorCode": "TOO_MANY_REQUESTS"}`)
+		case "key-revoked":
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errorCode": "INVALID_API_KEY"}`)
+		default:
+			w.Header().Set("X-RateLimit-Limit-day", "100")
+			w.Header().Set("X-RateLimit-Remaining-day", "42")
+			fmt.Fprint(w, mockIndexesResponse)
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Meta.Indexes(context.Background()); err != nil {
+			t.Fatalf("Meta.Indexes returned error: %v", err)
+		}
+	}
+	want := []string{"key-limited", "key-revoked", "key-ok", "key-ok"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("used keys %v, want %v", got, want)
+	}
+
+	usage := pool.Usage()
+	if u := usage[0]; u.Requests != 1 || u.RateLimited != 1 || !u.AvailableAt.Equal(time.Date(2020, 5, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("rate limited key usage = %+v", u)
+	}
+	if u := usage[1]; !u.Unauthorized {
+		t.Errorf("revoked key usage = %+v, want Unauthorized", u)
+	}
+	if u := usage[2]; u.Key != "****y-ok" || u.Requests != 2 || u.RateLimits.Day != (Rate{Limit: 100, Remaining: 42}) {
+		t.Errorf("available key usage = %+v", u)
+	}
+
+	// The rate limited key is used again the next day.
+	now = now.Add(9 * time.Hour)
+	got = nil
+	client.Meta.Indexes(context.Background())
+	if len(got) == 0 || got[0] != "key-limited" {
+		t.Errorf("used keys %v the next day, want key-limited first", got)
+	}
+}
+
+func TestKeyPool_allKeysRateLimited(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.KeyPool(newTestKeyPool(t, RoundRobin, "key-a", "key-b"))
+
+	requests := 0
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"errorCode": "TOO_MANY_REQUESTS"}`)
+	})
+
+	_, err := client.Meta.Indexes(context.Background())
+	if !hasErrorCode(err, "TOO_MANY_REQUESTS") {
+		t.Errorf("Meta.Indexes returned %v, want TOO_MANY_REQUESTS", err)
+	}
+	if requests != 2 {
+		t.Errorf("sent %d requests, want 2", requests)
+	}
+}
+
+func TestKeyPool_rateLimitedAndRevoked(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.KeyPool(newTestKeyPool(t, RoundRobin, "key-limited", "key-revoked"))
+
+	requests := 0
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("apiKey") == "key-revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errorCode": "INVALID_API_KEY"}`)
+			return
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"errorCode": "TOO_MANY_REQUESTS"}`)
+	})
+
+	for i := 1; i <= 3; i++ {
+		if _, err := client.Meta.Indexes(context.Background()); err == nil {
+			t.Fatal("Meta.Indexes returned nil error")
+		}
+		if max := 2 * i; requests > max {
+			t.Fatalf("sent %d requests in %d calls, want at most %d", requests, i, max)
+		}
+	}
+}
+
+func TestNewKeyPool_missingKey(t *testing.T) {
+	if _, err := NewKeyPool(RoundRobin); err == nil {
+		t.Error("NewKeyPool returned nil error without keys")
+	}
+	if _, err := NewKeyPool(RoundRobin, "key", ""); err == nil {
+		t.Error("NewKeyPool returned nil error for an empty key")
+	}
+}
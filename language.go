@@ -0,0 +1,168 @@
+package airly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Language is a language of textual content returned by the Airly API,
+// such as index descriptions and error messages.
+// https://developer.airly.eu/docs#general.language
+type Language string
+
+// Languages supported by the Airly API.
+const (
+	English Language = "en"
+	Polish  Language = "pl"
+)
+
+// SupportedLanguages lists the languages supported by the Airly API,
+// English is the default one.
+var SupportedLanguages = []Language{English, Polish}
+
+// Supported reports whether the Airly API supports l.
+func (l Language) Supported() bool {
+	for _, s := range SupportedLanguages {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLanguage returns the supported language preferred by s, which is
+// a language tag such as "pl-PL" or an Accept-Language header value such
+// as "de-DE,pl;q=0.8,en;q=0.5". The wildcard "*" stands for English.
+func ParseLanguage(s string) (Language, error) {
+	type candidate struct {
+		lang Language
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(s, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if !strings.HasPrefix(f, "q=") {
+				continue
+			}
+			v, err := strconv.ParseFloat(f[2:], 64)
+			if err != nil || v < 0 || v > 1 {
+				return "", fmt.Errorf("airly: invalid language %q: bad quality %q", s, f)
+			}
+			q = v
+		}
+		primary := strings.ToLower(tag)
+		if i := strings.IndexAny(primary, "-_"); i >= 0 {
+			primary = primary[:i]
+		}
+		if !validPrimaryTag(primary) {
+			return "", fmt.Errorf("airly: invalid language %q", s)
+		}
+		lang := Language(primary)
+		if primary == "*" {
+			lang = English
+		}
+		if lang.Supported() && q > 0 {
+			candidates = append(candidates, candidate{lang, q})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("airly: no supported language in %q", s)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].lang, nil
+}
+
+func validPrimaryTag(tag string) bool {
+	if tag == "*" {
+		return true
+	}
+	if len(tag) < 2 || len(tag) > 8 {
+		return false
+	}
+	for _, r := range tag {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+type languageKey struct{}
+
+// WithLanguage returns a context that makes requests sent with it use lang
+// instead of the client's language, without modifying the shared client.
+func WithLanguage(ctx context.Context, lang Language) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// requestLanguage returns the language of requests sent with ctx,
+// empty for the API default.
+func (c *Client) requestLanguage(ctx context.Context) Language {
+	if lang, ok := ctx.Value(languageKey{}).(Language); ok {
+		return lang
+	}
+	return c.language
+}
+
+// checkLanguage returns a ValidationError for an unsupported language.
+func checkLanguage(lang Language) error {
+	if lang == "" || lang.Supported() {
+		return nil
+	}
+	return ValidationError{
+		Params: []InvalidParam{newInvalidParam("Accept-Language", "%q is not a supported language", string(lang))},
+	}
+}
+
+// translations of the messages generated by the package by language,
+// keyed by their English format.
+var translations = map[Language]map[string]string{
+	Polish: {
+		"airly: unexpected HTTP %d %s (empty error)":         "airly: nieoczekiwana odpowiedź HTTP %d %s (pusty błąd)",
+		"airly: invalid opts: ":                              "airly: nieprawidłowe parametry: ",
+		"%v is not a latitude in range [-90, 90]":            "%v nie jest szerokością geograficzną z zakresu [-90, 90]",
+		"%v is not a longitude in range [-180, 180]":         "%v nie jest długością geograficzną z zakresu [-180, 180]",
+		"%v is not a non-negative distance":                  "%v nie jest nieujemną odległością",
+		"%d is neither a positive number nor %d (unlimited)": "%d nie jest ani liczbą dodatnią, ani %d (bez limitu)",
+		"%q is not a supported language":                     "%q nie jest obsługiwanym językiem",
+		"is required":                                        "jest wymagany",
+	},
+}
+
+// sprintf formats the translation of format to l, or format itself
+// when there is no translation.
+func (l Language) sprintf(format string, args ...interface{}) string {
+	if t, ok := translations[l][format]; ok {
+		format = t
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// localize translates the messages of the package in err to lang.
+func localize(err error, lang Language) error {
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+	params := make([]InvalidParam, len(verr.Params))
+	for i, p := range verr.Params {
+		if p.format != "" {
+			p.Message = lang.sprintf(p.format, p.args...)
+		}
+		params[i] = p
+	}
+	return ValidationError{Params: params, lang: lang}
+}
@@ -0,0 +1,112 @@
+package airly
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Language
+		wantErr bool
+	}{
+		{in: "pl", want: Polish},
+		{in: "PL", want: Polish},
+		{in: "en-GB", want: English},
+		{in: "pl_PL", want: Polish},
+		{in: "pl-PL,pl;q=0.9,en;q=0.8", want: Polish},
+		{in: "de-DE,en;q=0.5,pl;q=0.8", want: Polish},
+		{in: "pl;q=0, en", want: English},
+		{in: "*", want: English},
+		{in: "de, *;q=0.5", want: English},
+		{in: "*;q=0.5, pl", want: Polish},
+		{in: "de", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "pl;q=2", wantErr: true},
+		{in: "p1", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: "en,-", wantErr: true},
+		{in: "_;q=0.5", wantErr: true},
+		{in: "-en", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLanguage(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLanguage(%q) returned error %v, want error %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLanguage(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLanguage(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.Language(English)
+
+	var got []string
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Accept-Language"))
+		fmt.Fprint(w, `[]`)
+	})
+
+	if _, err := client.Meta.Indexes(WithLanguage(context.Background(), Polish)); err != nil {
+		t.Fatalf("Meta.Indexes: %v", err)
+	}
+	if _, err := client.Meta.Indexes(context.Background()); err != nil {
+		t.Fatalf("Meta.Indexes: %v", err)
+	}
+	if got[0] != "pl" || got[1] != "en" {
+		t.Errorf("Accept-Language headers %q, want [pl en]", got)
+	}
+}
+
+func TestClient_unsupportedLanguage(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.Language("de")
+
+	mux.HandleFunc("/installations/8077", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent in an unsupported language")
+	})
+
+	_, err := client.Installation.ByID(context.Background(), 8077)
+	var verr ValidationError
+	if !errors.As(err, &verr) || verr.Params[0].Parameter != "Accept-Language" {
+		t.Errorf("Installation.ByID returned %v, want ValidationError of Accept-Language", err)
+	}
+}
+
+func TestClient_translatedMessages(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.Language(Polish)
+
+	mux.HandleFunc("/installations/8077", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, `{}`)
+	})
+
+	_, err := client.Installation.ByID(context.Background(), 8077)
+	if want := "airly: nieoczekiwana odpowiedź HTTP 502 Bad Gateway (pusty błąd)"; err == nil || err.Error() != want {
+		t.Errorf("Installation.ByID returned %v, want %q", err, want)
+	}
+
+	_, err = client.Installation.Nearest(context.Background(), NewNearestInstallationOpts(91, 0))
+	if want := "airly: nieprawidłowe parametry: lat: 91 nie jest szerokością geograficzną z zakresu [-90, 90]"; err == nil || err.Error() != want {
+		t.Errorf("Installation.Nearest returned %v, want %q", err, want)
+	}
+
+	ctx := WithLanguage(context.Background(), English)
+	_, err = client.Installation.Nearest(ctx, NewNearestInstallationOpts(91, 0))
+	if want := "airly: invalid opts: lat: 91 is not a latitude in range [-90, 90]"; err == nil || err.Error() != want {
+		t.Errorf("Installation.Nearest returned %v, want %q", err, want)
+	}
+}
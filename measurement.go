@@ -1,26 +1,39 @@
 package airly
 
 import (
+	"context"
 	"time"
 )
 
+// MeasurementAPI is the set of measurement operations, satisfied by
+// MeasurementService and FakeMeasurementService.
+type MeasurementAPI interface {
+	ByID(ctx context.Context, opts *ByIDMeasurementOpts) (Measurement, error)
+	Nearest(ctx context.Context, opts *NearestMeasurementOpts) (Measurement, error)
+	ForPoint(ctx context.Context, opts *ForPointMeasurementOpts) (Measurement, error)
+}
+
+var _ MeasurementAPI = (*MeasurementService)(nil)
+
 // MeasurementService is used to measurement operations.
 // https://developer.airly.eu/docs#endpoints.measurements
 type MeasurementService struct {
 	client *Client
 }
 
+// IndexKind is the name of an air quality index, requested with IndexType
+// and described by the IndexType returned by MetaService.Indexes.
 // https://developer.airly.eu/docs#concepts.indexes
-type indexType string
+type IndexKind string
 
 const (
 	// AirlyCAQI is an Airly quality index.
 	// https://developer.airly.eu/docs#concepts.indexes.airlycaqi
-	AirlyCAQI indexType = "AIRLY_CAQI"
+	AirlyCAQI IndexKind = "AIRLY_CAQI"
 	// CAQI is a European air quality index.
-	CAQI indexType = "CAQI"
+	CAQI IndexKind = "CAQI"
 	// PIJP is a Polish air quality index.
-	PIJP indexType = "PIJP"
+	PIJP IndexKind = "PIJP"
 )
 
 // Value represents the name of the measurement (e.g., PM2.5)
@@ -56,6 +69,22 @@ type Data struct {
 	Values       []Value    `json:"values"`
 	Indexes      []Index    `json:"indexes"`
 	Standards    []Standard `json:"standards"`
+	// LocalModel names the model that generated the data locally,
+	// e.g. by the forecast package. It is empty for API data.
+	LocalModel string `json:"localModel,omitempty"`
+	// Flags are data quality issues of the values by value name found
+	// locally, e.g. by the quality package. They are empty for API data.
+	Flags map[string][]string `json:"flags,omitempty"`
+}
+
+// Value returns the value named name, e.g. "PM25", and whether d has it.
+func (d Data) Value(name string) (float64, bool) {
+	for _, v := range d.Values {
+		if v.Name == name {
+			return v.Value, true
+		}
+	}
+	return 0, false
 }
 
 // Measurement is a response format that contains measurements
@@ -66,93 +95,163 @@ type Measurement struct {
 	Forecast []Data `json:"forecast"`
 }
 
-type byIDMeasurementOpts struct {
-	*urlQuery
+// ByIDMeasurementOpts holds params of the installation id measurement query.
+type ByIDMeasurementOpts struct {
+	urlQuery
 }
 
 // NewByIDMeasurementOpts is an opts builder for the installation id measurement query.
-func NewByIDMeasurementOpts(id int64) *byIDMeasurementOpts {
-	return &byIDMeasurementOpts{
-		NewURLQuery().setInstallationID(id),
+func NewByIDMeasurementOpts(id int64) *ByIDMeasurementOpts {
+	return &ByIDMeasurementOpts{
+		*NewURLQuery().setInstallationID(id),
 	}
 }
 
-func (q *byIDMeasurementOpts) IncludeWind(wind bool) *byIDMeasurementOpts {
+// Clone returns a copy of q that can be modified independently.
+func (q *ByIDMeasurementOpts) Clone() *ByIDMeasurementOpts {
+	return &ByIDMeasurementOpts{*q.clone()}
+}
+
+// Validate reports all problems found in the query params,
+// including missing ones in a zero value.
+func (q *ByIDMeasurementOpts) Validate() error {
+	return q.validate("installationId")
+}
+
+// IncludeWind sets whether wind measurements are returned.
+func (q *ByIDMeasurementOpts) IncludeWind(wind bool) *ByIDMeasurementOpts {
 	q.setIncludeWind(wind)
 	return q
 }
 
-func (q *byIDMeasurementOpts) IndexType(index indexType) *byIDMeasurementOpts {
+// IndexType sets the index calculated for the measurements.
+func (q *ByIDMeasurementOpts) IndexType(index IndexKind) *ByIDMeasurementOpts {
 	q.setIndexType(index)
 	return q
 }
 
 // ByID returns measurements for concrete installation given by installationID.
 // https://developer.airly.eu/docs#endpoints.measurements.installation
-func (c *MeasurementService) ByID(opts *byIDMeasurementOpts) (Measurement, error) {
+func (c *MeasurementService) ByID(ctx context.Context, opts *ByIDMeasurementOpts) (Measurement, error) {
+	v, _, err := c.ByIDWithResponse(ctx, opts)
+	return v, err
+}
+
+// ByIDWithResponse is like ByID, but also returns metadata of the API response.
+func (c *MeasurementService) ByIDWithResponse(ctx context.Context, opts *ByIDMeasurementOpts) (Measurement, *Response, error) {
+	if err := opts.Validate(); err != nil {
+		return Measurement{}, nil, localize(err, c.client.requestLanguage(ctx))
+	}
 	var measurement Measurement
-	err := c.client.get("measurements/installation", opts.opts, &measurement)
+	resp, err := c.client.get(ctx, Operation{Name: "Measurement.ByID"}, "measurements/installation", opts.opts, &measurement)
 	if err != nil {
-		return Measurement{}, err
+		return Measurement{}, resp, err
 	}
-	return measurement, nil
+	return measurement, resp, nil
 }
 
-type nearestMeasurementOpts struct {
-	*urlQuery
+// NearestMeasurementOpts holds params of the nearest measurement query.
+type NearestMeasurementOpts struct {
+	urlQuery
 }
 
 // NewNearestMeasurementOpts is an opts builder for the nearest measurement query.
-func NewNearestMeasurementOpts(lat, lng float64) *nearestMeasurementOpts {
-	return &nearestMeasurementOpts{
-		NewURLQuery().setLocation(lat, lng),
+// Out of range coordinates are reported by Validate.
+func NewNearestMeasurementOpts(lat, lng float64) *NearestMeasurementOpts {
+	return &NearestMeasurementOpts{
+		*NewURLQuery().setLocation(lat, lng),
 	}
 }
 
-func (q *nearestMeasurementOpts) MaxDistance(km float64) *nearestMeasurementOpts {
+// Clone returns a copy of q that can be modified independently.
+func (q *NearestMeasurementOpts) Clone() *NearestMeasurementOpts {
+	return &NearestMeasurementOpts{*q.clone()}
+}
+
+// Validate reports all problems found in the query params,
+// including missing ones in a zero value.
+func (q *NearestMeasurementOpts) Validate() error {
+	return q.validate("lat", "lng")
+}
+
+// MaxDistance limits the search to installations within km kilometers.
+func (q *NearestMeasurementOpts) MaxDistance(km float64) *NearestMeasurementOpts {
 	q.setMaxDistance(km)
 	return q
 }
 
-func (q *nearestMeasurementOpts) IndexType(index indexType) *nearestMeasurementOpts {
+// IndexType sets the index calculated for the measurements.
+func (q *NearestMeasurementOpts) IndexType(index IndexKind) *NearestMeasurementOpts {
 	q.setIndexType(index)
 	return q
 }
 
 // Nearest returns measurement for an installation closest to a given location.
 // https://developer.airly.eu/docs#endpoints.measurements.nearest
-func (c *MeasurementService) Nearest(opts *nearestMeasurementOpts) (Measurement, error) {
+func (c *MeasurementService) Nearest(ctx context.Context, opts *NearestMeasurementOpts) (Measurement, error) {
+	v, _, err := c.NearestWithResponse(ctx, opts)
+	return v, err
+}
+
+// NearestWithResponse is like Nearest, but also returns metadata of the API response.
+func (c *MeasurementService) NearestWithResponse(ctx context.Context, opts *NearestMeasurementOpts) (Measurement, *Response, error) {
+	if err := opts.Validate(); err != nil {
+		return Measurement{}, nil, localize(err, c.client.requestLanguage(ctx))
+	}
 	var measurement Measurement
-	err := c.client.get("measurements/nearest", opts.opts, &measurement)
+	resp, err := c.client.get(ctx, Operation{Name: "Measurement.Nearest"}, "measurements/nearest", opts.opts, &measurement)
 	if err != nil {
-		return Measurement{}, err
+		return Measurement{}, resp, err
 	}
-	return measurement, nil
+	return measurement, resp, nil
 }
 
-type forPointMeasurementOpts struct {
-	*urlQuery
+// ForPointMeasurementOpts holds params of the point measurement query.
+type ForPointMeasurementOpts struct {
+	urlQuery
 }
 
 // NewForPointMeasurementOpts is an opts builder for the point measurement query.
-func NewForPointMeasurementOpts(lat, lng float64) *forPointMeasurementOpts {
-	return &forPointMeasurementOpts{
-		NewURLQuery().setLocation(lat, lng),
+// Out of range coordinates are reported by Validate.
+func NewForPointMeasurementOpts(lat, lng float64) *ForPointMeasurementOpts {
+	return &ForPointMeasurementOpts{
+		*NewURLQuery().setLocation(lat, lng),
 	}
 }
 
-func (q *forPointMeasurementOpts) IndexType(index indexType) *forPointMeasurementOpts {
+// Clone returns a copy of q that can be modified independently.
+func (q *ForPointMeasurementOpts) Clone() *ForPointMeasurementOpts {
+	return &ForPointMeasurementOpts{*q.clone()}
+}
+
+// Validate reports all problems found in the query params,
+// including missing ones in a zero value.
+func (q *ForPointMeasurementOpts) Validate() error {
+	return q.validate("lat", "lng")
+}
+
+// IndexType sets the index calculated for the measurements.
+func (q *ForPointMeasurementOpts) IndexType(index IndexKind) *ForPointMeasurementOpts {
 	q.setIndexType(index)
 	return q
 }
 
 // ForPoint returns measurements for any geographical location.
 // https://developer.airly.eu/docs#endpoints.measurements.point
-func (c *MeasurementService) ForPoint(opts *forPointMeasurementOpts) (Measurement, error) {
+func (c *MeasurementService) ForPoint(ctx context.Context, opts *ForPointMeasurementOpts) (Measurement, error) {
+	v, _, err := c.ForPointWithResponse(ctx, opts)
+	return v, err
+}
+
+// ForPointWithResponse is like ForPoint, but also returns metadata of the API response.
+func (c *MeasurementService) ForPointWithResponse(ctx context.Context, opts *ForPointMeasurementOpts) (Measurement, *Response, error) {
+	if err := opts.Validate(); err != nil {
+		return Measurement{}, nil, localize(err, c.client.requestLanguage(ctx))
+	}
 	var measurement Measurement
-	err := c.client.get("measurements/point", opts.opts, &measurement)
+	resp, err := c.client.get(ctx, Operation{Name: "Measurement.ForPoint"}, "measurements/point", opts.opts, &measurement)
 	if err != nil {
-		return Measurement{}, err
+		return Measurement{}, resp, err
 	}
-	return measurement, nil
+	return measurement, resp, nil
 }
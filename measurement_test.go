@@ -1,6 +1,7 @@
 package airly
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -19,7 +20,7 @@ func TestMeasurementService_ByID(t *testing.T) {
 		fmt.Fprint(w, mockMeasurementResponse)
 	})
 
-	got, err := client.Measurement.ByID(opt)
+	got, err := client.Measurement.ByID(context.Background(), opt)
 	if err != nil {
 		t.Errorf("Measurement.ByID: %v", err)
 	}
@@ -39,7 +40,7 @@ func TestMeasurementService_Nearest(t *testing.T) {
 		fmt.Fprint(w, mockMeasurementResponse)
 	})
 
-	got, err := client.Measurement.Nearest(opt)
+	got, err := client.Measurement.Nearest(context.Background(), opt)
 	if err != nil {
 		t.Errorf("Measurement.Nearest: %v", err)
 	}
@@ -59,7 +60,7 @@ func TestMeasurementService_ForPoint(t *testing.T) {
 		fmt.Fprint(w, mockMeasurementResponse)
 	})
 
-	got, err := client.Measurement.ForPoint(opt)
+	got, err := client.Measurement.ForPoint(context.Background(), opt)
 	if err != nil {
 		t.Errorf("Measurement.ForPoint: %v", err)
 	}
@@ -68,6 +69,24 @@ func TestMeasurementService_ForPoint(t *testing.T) {
 	}
 }
 
+func TestMeasurementService_zeroOpts(t *testing.T) {
+	client, _, teardown := setup()
+	defer teardown()
+	ctx := context.Background()
+
+	var byID ByIDMeasurementOpts
+	var nearest NearestMeasurementOpts
+	var point ForPointMeasurementOpts
+	_, err1 := client.Measurement.ByID(ctx, &byID)
+	_, err2 := client.Measurement.Nearest(ctx, &nearest)
+	_, err3 := client.Measurement.ForPoint(ctx, point.IndexType(CAQI))
+	for i, err := range []error{err1, err2, err3} {
+		if _, ok := err.(ValidationError); !ok {
+			t.Errorf("call %d returned %v, want ValidationError", i, err)
+		}
+	}
+}
+
 var mockMeasurementResponse = `
 	{
 		"current":{
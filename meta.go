@@ -1,5 +1,18 @@
 package airly
 
+import (
+	"context"
+)
+
+// MetaAPI is the set of meta operations, satisfied by
+// MetaService and FakeMetaService.
+type MetaAPI interface {
+	Indexes(ctx context.Context) ([]IndexType, error)
+	Measurements(ctx context.Context) ([]MeasurementType, error)
+}
+
+var _ MetaAPI = (*MetaService)(nil)
+
 // MetaService is used to meta operations.
 // https://developer.airly.eu/docs#endpoints.meta
 type MetaService struct {
@@ -32,23 +45,47 @@ type MeasurementType struct {
 // Indexes return a list of all the index types supported in the API along
 // with lists of levels defined per each index type.
 // https://developer.airly.eu/docs#endpoints.meta.indexes
-func (c *MetaService) Indexes() ([]IndexType, error) {
+func (c *MetaService) Indexes(ctx context.Context) ([]IndexType, error) {
+	v, _, err := c.IndexesWithResponse(ctx)
+	return v, err
+}
+
+// IndexesWithResponse is like Indexes, but also returns metadata of the API response.
+func (c *MetaService) IndexesWithResponse(ctx context.Context) ([]IndexType, *Response, error) {
+	if data, resp, ok := c.client.preferredMeta(ctx); ok {
+		return data.Indexes, resp, nil
+	}
 	var indexTypes []IndexType
-	err := c.client.get("meta/indexes", nil, &indexTypes)
+	resp, err := c.client.get(ctx, Operation{Name: "Meta.Indexes"}, "meta/indexes", nil, &indexTypes)
+	if data, embedded, ok := c.client.offlineMeta(ctx, resp, err); ok {
+		return data.Indexes, embedded, nil
+	}
 	if err != nil {
-		return nil, err
+		return nil, resp, err
 	}
-	return indexTypes, nil
+	return indexTypes, resp, nil
 }
 
 // Measurements return a list of all the measurement types supported
 // in the API along with their names and units.
 // https://developer.airly.eu/docs#endpoints.meta.measurements
-func (c *MetaService) Measurements() ([]MeasurementType, error) {
+func (c *MetaService) Measurements(ctx context.Context) ([]MeasurementType, error) {
+	v, _, err := c.MeasurementsWithResponse(ctx)
+	return v, err
+}
+
+// MeasurementsWithResponse is like Measurements, but also returns metadata of the API response.
+func (c *MetaService) MeasurementsWithResponse(ctx context.Context) ([]MeasurementType, *Response, error) {
+	if data, resp, ok := c.client.preferredMeta(ctx); ok {
+		return data.Measurements, resp, nil
+	}
 	var measurementTypes []MeasurementType
-	err := c.client.get("meta/measurements", nil, &measurementTypes)
+	resp, err := c.client.get(ctx, Operation{Name: "Meta.Measurements"}, "meta/measurements", nil, &measurementTypes)
+	if data, embedded, ok := c.client.offlineMeta(ctx, resp, err); ok {
+		return data.Measurements, embedded, nil
+	}
 	if err != nil {
-		return nil, err
+		return nil, resp, err
 	}
-	return measurementTypes, nil
+	return measurementTypes, resp, nil
 }
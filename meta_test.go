@@ -1,6 +1,7 @@
 package airly
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -16,7 +17,7 @@ func TestMetaService_Indexes(t *testing.T) {
 		fmt.Fprint(w, mockIndexesResponse)
 	})
 
-	got, err := client.Meta.Indexes()
+	got, err := client.Meta.Indexes(context.Background())
 	if err != nil {
 		t.Errorf("Meta.Indexes: %v", err)
 	}
@@ -34,7 +35,7 @@ func TestMetaService_Measurements(t *testing.T) {
 		fmt.Fprint(w, mockMeasurementsResponse)
 	})
 
-	got, err := client.Meta.Measurements()
+	got, err := client.Meta.Measurements(context.Background())
 	if err != nil {
 		t.Errorf("Meta.Measurements: %v", err)
 	}
@@ -0,0 +1,131 @@
+package airly
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+)
+
+// DoerFunc is an adapter to allow the use of ordinary functions as HTTPDoer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the HTTPDoer sending API requests, e.g. to log them.
+// Middlewares must not modify the request, but clone it instead.
+type Middleware func(next HTTPDoer) HTTPDoer
+
+// Use appends middlewares to the chain wrapping the client's HTTPDoer.
+// The first middleware is the outermost one. Middlewares only see requests
+// that are sent, not those answered from the cache.
+func (c *Client) Use(mw ...Middleware) *Client {
+	c.middlewares = append(c.middlewares, mw...)
+	doer := c.client
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		doer = c.middlewares[i](doer)
+	}
+	c.doer = doer
+	return c
+}
+
+// LoggingMiddleware logs the method, URL, status and duration of requests.
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	return func(next HTTPDoer) HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Do(req)
+			if err != nil {
+				logger.Printf("airly: %s %s failed after %s: %v", req.Method, req.URL, time.Since(start), err)
+				return resp, err
+			}
+			logger.Printf("airly: %s %s %d in %s", req.Method, req.URL, resp.StatusCode, time.Since(start))
+			return resp, nil
+		})
+	}
+}
+
+// RequestStats describes a single sent request.
+type RequestStats struct {
+	Method string
+	// Path is the URL path, without the query.
+	Path string
+	// StatusCode is zero when Err is set.
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// MetricsMiddleware calls observe with stats of every request,
+// e.g. to update counters and latency histograms.
+func MetricsMiddleware(observe func(RequestStats)) Middleware {
+	return func(next HTTPDoer) HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Do(req)
+			stats := RequestStats{
+				Method:   req.Method,
+				Path:     req.URL.Path,
+				Duration: time.Since(start),
+				Err:      err,
+			}
+			if resp != nil {
+				stats.StatusCode = resp.StatusCode
+			}
+			observe(stats)
+			return resp, err
+		})
+	}
+}
+
+// HeaderMiddleware sets header on every request, e.g. a User-Agent.
+func HeaderMiddleware(header http.Header) Middleware {
+	return func(next HTTPDoer) HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for k, v := range header {
+				req.Header[k] = append([]string(nil), v...)
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+// DumpMiddleware writes requests and responses including bodies to w,
+// for debugging. The apiKey header is redacted.
+func DumpMiddleware(w io.Writer) Middleware {
+	var mu sync.Mutex
+	return func(next HTTPDoer) HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			redactedReq := req.Clone(req.Context())
+			if redactedReq.Header.Get("apiKey") != "" {
+				redactedReq.Header.Set("apiKey", redacted)
+			}
+			reqDump, err := httputil.DumpRequestOut(redactedReq, true)
+			if err != nil {
+				return nil, fmt.Errorf("dump request: %w", err)
+			}
+
+			resp, err := next.Do(req)
+			if err != nil {
+				return resp, err
+			}
+			respDump, err := httputil.DumpResponse(resp, true)
+			if err != nil {
+				resp.Body.Close()
+				return nil, fmt.Errorf("dump response: %w", err)
+			}
+
+			mu.Lock()
+			fmt.Fprintf(w, "%s\n%s\n", reqDump, respDump)
+			mu.Unlock()
+			return resp, nil
+		})
+	}
+}
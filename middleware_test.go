@@ -0,0 +1,59 @@
+package airly
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClient_Use(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/meta/measurements", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("User-Agent"); got != "test-agent" {
+			t.Errorf("User-Agent %q, want test-agent", got)
+		}
+		fmt.Fprint(w, mockMeasurementsResponse)
+	})
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next HTTPDoer) HTTPDoer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.Do(req)
+			})
+		}
+	}
+
+	var stats []RequestStats
+	var logs, dump bytes.Buffer
+	client.Use(trace("outer"), trace("inner")).Use(
+		HeaderMiddleware(http.Header{"User-Agent": {"test-agent"}}),
+		MetricsMiddleware(func(s RequestStats) { stats = append(stats, s) }),
+		LoggingMiddleware(log.New(&logs, "", 0)),
+		DumpMiddleware(&dump),
+	)
+
+	if _, err := client.Meta.Measurements(context.Background()); err != nil {
+		t.Fatalf("Meta.Measurements: %v", err)
+	}
+
+	if got := strings.Join(order, ","); got != "outer,inner" {
+		t.Errorf("middlewares called in order %s, want outer,inner", got)
+	}
+	if len(stats) != 1 || stats[0].Path != "/meta/measurements" || stats[0].StatusCode != http.StatusOK {
+		t.Errorf("recorded stats %+v", stats)
+	}
+	if !strings.Contains(logs.String(), "/meta/measurements 200 in ") {
+		t.Errorf("logged %q", logs.String())
+	}
+	if d := dump.String(); strings.Contains(d, "apiKey: apiKey") || !strings.Contains(d, "Apikey: REDACTED") || !strings.Contains(d, `"unit":"µg/m³"`) {
+		t.Errorf("dumped %q", d)
+	}
+}
@@ -0,0 +1,139 @@
+// Package otelairly traces and measures Airly API calls with OpenTelemetry.
+//
+// It lives in a separate module so that go-airly itself stays free of
+// dependencies:
+//
+//	inst, err := otelairly.New()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	client.Instrument(inst)
+package otelairly
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lsjurczak/go-airly"
+)
+
+const scope = "github.com/lsjurczak/go-airly/otelairly"
+
+// Attribute keys set on spans and metrics.
+const (
+	InstallationIDKey = attribute.Key("airly.installation.id")
+	LatKey            = attribute.Key("airly.location.lat")
+	LngKey            = attribute.Key("airly.location.lng")
+	IndexTypeKey      = attribute.Key("airly.index_type")
+	OperationKey      = attribute.Key("airly.operation")
+	StatusCodeKey     = attribute.Key("http.response.status_code")
+)
+
+// Option configures the Instrumentation.
+type Option func(*config)
+
+type config struct {
+	tp trace.TracerProvider
+	mp metric.MeterProvider
+}
+
+// WithTracerProvider sets the tracer provider, the global one by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) { c.tp = tp }
+}
+
+// WithMeterProvider sets the meter provider, the global one by default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) { c.mp = mp }
+}
+
+// Instrumentation is an airly.Instrumentation starting a span per service
+// method call and recording call duration, errors and remaining quota.
+type Instrumentation struct {
+	tracer    trace.Tracer
+	duration  metric.Float64Histogram
+	errors    metric.Int64Counter
+	remaining metric.Int64Gauge
+}
+
+var _ airly.Instrumentation = (*Instrumentation)(nil)
+
+// New creates an Instrumentation.
+func New(opts ...Option) (*Instrumentation, error) {
+	cfg := config{
+		tp: otel.GetTracerProvider(),
+		mp: otel.GetMeterProvider(),
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	meter := cfg.mp.Meter(scope)
+	duration, err := meter.Float64Histogram("airly.client.duration",
+		metric.WithDescription("Duration of Airly API calls."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	errs, err := meter.Int64Counter("airly.client.errors",
+		metric.WithDescription("Number of failed Airly API calls."))
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := meter.Int64Gauge("airly.client.quota.remaining",
+		metric.WithDescription("Remaining daily requests of the api key."))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Instrumentation{
+		tracer:    cfg.tp.Tracer(scope),
+		duration:  duration,
+		errors:    errs,
+		remaining: remaining,
+	}, nil
+}
+
+// StartOperation starts a span named after the service method.
+func (i *Instrumentation) StartOperation(ctx context.Context, op airly.Operation) (context.Context, func(airly.OperationResult)) {
+	attrs := []attribute.KeyValue{OperationKey.String(op.Name)}
+	if op.InstallationID != 0 {
+		attrs = append(attrs, InstallationIDKey.Int64(op.InstallationID))
+	}
+	if op.HasLocation {
+		attrs = append(attrs, LatKey.Float64(op.Lat), LngKey.Float64(op.Lng))
+	}
+	if op.IndexType != "" {
+		attrs = append(attrs, IndexTypeKey.String(op.IndexType))
+	}
+
+	ctx, span := i.tracer.Start(ctx, op.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	return ctx, func(res airly.OperationResult) {
+		defer span.End()
+
+		metricAttrs := []attribute.KeyValue{OperationKey.String(op.Name)}
+		if res.StatusCode != 0 {
+			span.SetAttributes(StatusCodeKey.Int(res.StatusCode))
+			metricAttrs = append(metricAttrs, StatusCodeKey.Int(res.StatusCode))
+		}
+		set := metric.WithAttributes(metricAttrs...)
+
+		i.duration.Record(ctx, res.Duration.Seconds(), set)
+		if res.RateLimitRemaining >= 0 {
+			i.remaining.Record(ctx, int64(res.RateLimitRemaining))
+		}
+		if res.Err != nil {
+			span.RecordError(res.Err)
+			span.SetStatus(codes.Error, res.Err.Error())
+			i.errors.Add(ctx, 1, set)
+		}
+	}
+}
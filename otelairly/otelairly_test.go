@@ -0,0 +1,77 @@
+package otelairly
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/lsjurczak/go-airly"
+)
+
+func TestInstrumentation(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	inst, err := New(WithTracerProvider(tp), WithMeterProvider(mp))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, end := inst.StartOperation(context.Background(), airly.Operation{
+		Name:           "Measurement.ByID",
+		InstallationID: 8077,
+		IndexType:      "AIRLY_CAQI",
+	})
+	end(airly.OperationResult{StatusCode: 200, Duration: 20 * time.Millisecond, RateLimitRemaining: 99})
+
+	_, end = inst.StartOperation(context.Background(), airly.Operation{
+		Name:        "Measurement.ForPoint",
+		HasLocation: true,
+		Lat:         50.06,
+		Lng:         19.94,
+	})
+	end(airly.OperationResult{Err: errors.New("timeout"), RateLimitRemaining: -1})
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("ended %d spans, want 2", len(ended))
+	}
+	if got := ended[0].Name(); got != "Measurement.ByID" {
+		t.Errorf("span name %q, want Measurement.ByID", got)
+	}
+	attrs := map[string]interface{}{}
+	for _, kv := range ended[0].Attributes() {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	if attrs["airly.installation.id"] != int64(8077) || attrs["http.response.status_code"] != int64(200) || attrs["airly.index_type"] != "AIRLY_CAQI" {
+		t.Errorf("span attributes %v", attrs)
+	}
+	if got := ended[1].Status().Code; got != codes.Error {
+		t.Errorf("failed span status %v, want Error", got)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	found := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			found[m.Name] = true
+		}
+	}
+	for _, name := range []string{"airly.client.duration", "airly.client.errors", "airly.client.quota.remaining"} {
+		if !found[name] {
+			t.Errorf("metric %s not recorded", name)
+		}
+	}
+}
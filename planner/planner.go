@@ -0,0 +1,285 @@
+// Package planner finds the best time windows for outdoor activity
+// in Airly forecasts.
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+	"github.com/lsjurczak/go-airly/series"
+)
+
+const (
+	defaultIndex = "AIRLY_CAQI"
+	defaultLimit = 3
+)
+
+// Levels are the levels of Airly indexes from the best to the worst.
+var Levels = []string{"VERY_LOW", "LOW", "MEDIUM", "HIGH", "VERY_HIGH", "EXTREME", "AIRMAGEDDON"}
+
+// Options configures Plan.
+type Options struct {
+	// Hours is the length of the windows, 1 if zero.
+	Hours int
+	// Start and Horizon limit the windows to the period from Start till
+	// Start+Horizon. Zero Start is the beginning of the forecast,
+	// zero Horizon is the end of the forecast.
+	Start   time.Time
+	Horizon time.Duration
+	// Index is the index used to rank the windows, AIRLY_CAQI if empty.
+	Index string
+	// MaxLevel is the worst allowed level of Index, e.g. "LOW",
+	// unlimited if empty. Hours without the index are not allowed.
+	MaxLevel string
+	// MaxPM25 is the highest allowed PM2.5 concentration in µg/m³,
+	// unlimited if zero.
+	MaxPM25 float64
+	// Temperature in °C and Humidity in % limit the forecast values when
+	// set. Forecasts often leave them out, such hours are not checked.
+	Temperature *series.Range
+	Humidity    *series.Range
+	// Daylight limits the windows to hours between sunrise and sunset
+	// at the location when set, e.g. the installation's Location.
+	Daylight *airly.Location
+	// Limit is the maximum number of windows returned, 3 if zero.
+	Limit int
+}
+
+// Window is a period of consecutive forecast hours that satisfies
+// the constraints of Options.
+type Window struct {
+	From time.Time
+	Till time.Time
+	// Score ranks the windows, lower is better. It is the mean value of
+	// the index, or the mean PM2.5 concentration without the index.
+	Score float64
+	// MeanIndex, MaxIndex and WorstLevel summarize the index,
+	// they are NaN and empty without it.
+	MeanIndex  float64
+	MaxIndex   float64
+	WorstLevel string
+	// MaxPM25 is the highest PM2.5 concentration, NaN without it.
+	MaxPM25 float64
+	// Explanation lists why the window was chosen.
+	Explanation []string
+}
+
+// Plan returns the best windows of forecast, e.g. Measurement.Forecast,
+// ranked by Score. The windows do not overlap.
+func Plan(forecast []airly.Data, opts Options) ([]Window, error) {
+	if opts.Hours < 0 || opts.Limit < 0 || opts.Horizon < 0 {
+		return nil, errors.New("planner: negative Hours, Limit or Horizon")
+	}
+	if opts.Hours == 0 {
+		opts.Hours = 1
+	}
+	if opts.Limit == 0 {
+		opts.Limit = defaultLimit
+	}
+	if opts.Index == "" {
+		opts.Index = defaultIndex
+	}
+	maxLevel := len(Levels) - 1
+	if opts.MaxLevel != "" {
+		if maxLevel = levelRank(opts.MaxLevel); maxLevel < 0 {
+			return nil, fmt.Errorf("planner: unknown level %q", opts.MaxLevel)
+		}
+	}
+
+	hours := horizon(forecast, opts)
+	var candidates []Window
+	for i := 0; i+opts.Hours <= len(hours); i++ {
+		span := hours[i : i+opts.Hours]
+		if contiguous(span) && allowed(span, opts, maxLevel) {
+			candidates = append(candidates, summarize(span, opts))
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score < candidates[j].Score
+	})
+
+	var windows []Window
+	for _, w := range candidates {
+		if len(windows) == opts.Limit {
+			break
+		}
+		if overlaps(windows, w) {
+			continue
+		}
+		if len(windows) == 0 {
+			w.Explanation = append(w.Explanation, fmt.Sprintf("best of %d candidate windows", len(candidates)))
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// horizon returns the sorted forecast hours within the horizon of opts.
+func horizon(forecast []airly.Data, opts Options) []airly.Data {
+	hours := append([]airly.Data(nil), forecast...)
+	sort.SliceStable(hours, func(i, j int) bool {
+		return hours[i].FromDateTime.Before(hours[j].FromDateTime)
+	})
+	start := opts.Start
+	if start.IsZero() && len(hours) > 0 {
+		start = hours[0].FromDateTime
+	}
+	var out []airly.Data
+	for _, d := range hours {
+		if d.FromDateTime.Before(start) {
+			continue
+		}
+		if opts.Horizon > 0 && d.TillDateTime.After(start.Add(opts.Horizon)) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+func contiguous(span []airly.Data) bool {
+	for i := 1; i < len(span); i++ {
+		if !span[i].FromDateTime.Equal(span[i-1].TillDateTime) {
+			return false
+		}
+	}
+	return true
+}
+
+func allowed(span []airly.Data, opts Options, maxLevel int) bool {
+	for _, d := range span {
+		if opts.MaxLevel != "" {
+			idx, ok := index(d, opts.Index)
+			if !ok || levelRank(idx.Level) < 0 || levelRank(idx.Level) > maxLevel {
+				return false
+			}
+		}
+		if pm25, ok := d.Value("PM25"); ok && opts.MaxPM25 > 0 && pm25 > opts.MaxPM25 {
+			return false
+		}
+		if t, ok := d.Value("TEMPERATURE"); ok && opts.Temperature != nil && !opts.Temperature.Contains(t) {
+			return false
+		}
+		if h, ok := d.Value("HUMIDITY"); ok && opts.Humidity != nil && !opts.Humidity.Contains(h) {
+			return false
+		}
+		if opts.Daylight != nil {
+			sunrise, sunset := SunTimes(*opts.Daylight, d.FromDateTime)
+			if d.FromDateTime.Before(sunrise) || d.TillDateTime.After(sunset) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func summarize(span []airly.Data, opts Options) Window {
+	w := Window{
+		From:      span[0].FromDateTime,
+		Till:      span[len(span)-1].TillDateTime,
+		MeanIndex: math.NaN(),
+		MaxIndex:  math.NaN(),
+		MaxPM25:   math.NaN(),
+	}
+	var indexes, pm25s, temps, humidities []float64
+	worst := -1
+	for _, d := range span {
+		if idx, ok := index(d, opts.Index); ok {
+			indexes = append(indexes, idx.Value)
+			if r := levelRank(idx.Level); r > worst {
+				worst = r
+				w.WorstLevel = idx.Level
+			}
+		}
+		if v, ok := d.Value("PM25"); ok {
+			pm25s = append(pm25s, v)
+		}
+		if v, ok := d.Value("TEMPERATURE"); ok {
+			temps = append(temps, v)
+		}
+		if v, ok := d.Value("HUMIDITY"); ok {
+			humidities = append(humidities, v)
+		}
+	}
+
+	w.Score = math.Inf(1)
+	if len(pm25s) > 0 {
+		w.MaxPM25 = maxOf(pm25s)
+		w.Score = mean(pm25s)
+		w.Explanation = append(w.Explanation, fmt.Sprintf("PM2.5 at most %.1f µg/m³", w.MaxPM25))
+	}
+	if len(indexes) > 0 {
+		w.MeanIndex, w.MaxIndex = mean(indexes), maxOf(indexes)
+		w.Score = w.MeanIndex
+		w.Explanation = append([]string{
+			fmt.Sprintf("%s averages %.0f, at worst %.0f (%s)", opts.Index, w.MeanIndex, w.MaxIndex, w.WorstLevel),
+		}, w.Explanation...)
+	}
+	if len(temps) > 0 {
+		w.Explanation = append(w.Explanation, fmt.Sprintf("temperature %.0f to %.0f °C", minOf(temps), maxOf(temps)))
+	}
+	if len(humidities) > 0 {
+		w.Explanation = append(w.Explanation, fmt.Sprintf("humidity %.0f to %.0f %%", minOf(humidities), maxOf(humidities)))
+	}
+	if opts.Daylight != nil {
+		sunrise, sunset := SunTimes(*opts.Daylight, w.From)
+		w.Explanation = append(w.Explanation, fmt.Sprintf("in daylight, sunrise %s and sunset %s UTC",
+			sunrise.Format("15:04"), sunset.Format("15:04")))
+	}
+	return w
+}
+
+func overlaps(windows []Window, w Window) bool {
+	for _, o := range windows {
+		if w.From.Before(o.Till) && o.From.Before(w.Till) {
+			return true
+		}
+	}
+	return false
+}
+
+func levelRank(level string) int {
+	for i, l := range Levels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+func index(d airly.Data, name string) (airly.Index, bool) {
+	for _, idx := range d.Indexes {
+		if idx.Name == name {
+			return idx, true
+		}
+	}
+	return airly.Index{}, false
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func minOf(values []float64) float64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = math.Min(m, v)
+	}
+	return m
+}
+
+func maxOf(values []float64) float64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = math.Max(m, v)
+	}
+	return m
+}
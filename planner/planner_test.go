@@ -0,0 +1,163 @@
+package planner
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+	"github.com/lsjurczak/go-airly/airlytest"
+	"github.com/lsjurczak/go-airly/series"
+)
+
+func forecast() []airly.Data {
+	return airlytest.DefaultDataset().Measurements[9599].Forecast
+}
+
+func TestPlan(t *testing.T) {
+	loc := airlytest.DefaultDataset().Installations[0].Location
+	windows, err := Plan(forecast(), Options{Hours: 2, MaxLevel: "LOW", Daylight: &loc})
+	if err != nil {
+		t.Fatalf("Plan returned error: %v", err)
+	}
+
+	wantFrom := []time.Time{
+		time.Date(2020, 5, 8, 7, 0, 0, 0, time.UTC),
+		time.Date(2020, 5, 8, 9, 0, 0, 0, time.UTC),
+		time.Date(2020, 5, 8, 5, 0, 0, 0, time.UTC),
+	}
+	if len(windows) != len(wantFrom) {
+		t.Fatalf("Plan returned %d windows, want %d", len(windows), len(wantFrom))
+	}
+	for i, w := range windows {
+		if !w.From.Equal(wantFrom[i]) || w.Till.Sub(w.From) != 2*time.Hour {
+			t.Errorf("window %d is %v-%v, want 2h from %v", i, w.From, w.Till, wantFrom[i])
+		}
+		if w.WorstLevel != "VERY_LOW" {
+			t.Errorf("window %d WorstLevel = %q, want VERY_LOW", i, w.WorstLevel)
+		}
+		if i > 0 && w.Score < windows[i-1].Score {
+			t.Errorf("window %d scores %v, better than window %d", i, w.Score, i-1)
+		}
+	}
+	if got, want := windows[0].MaxPM25, 5.51; got != want {
+		t.Errorf("MaxPM25 = %v, want %v", got, want)
+	}
+	if len(windows[0].Explanation) == 0 {
+		t.Error("Explanation is empty")
+	}
+}
+
+func TestPlan_constraints(t *testing.T) {
+	loc := airly.Location{Latitude: 52.287217, Longitude: 21.108757}
+	tests := []struct {
+		name string
+		opts Options
+		want int
+	}{
+		{"no constraints", Options{Hours: 3, Limit: 100}, 7},
+		{"max level", Options{Hours: 3, MaxLevel: "VERY_LOW", Limit: 100}, 3},
+		{"max PM2.5", Options{Hours: 1, MaxPM25: 6, Limit: 100}, 3},
+		{"horizon", Options{Hours: 1, Start: airlytest.Epoch.Add(2 * time.Hour), Horizon: 3 * time.Hour, Limit: 100}, 3},
+		{"humidity not forecast", Options{Hours: 24, Humidity: &series.Range{Min: 0, Max: 1}}, 1},
+		{"daylight in San Francisco", Options{Hours: 1, Daylight: &airly.Location{Latitude: 37.7749, Longitude: -122.4194}, Limit: 100}, 13},
+		{"daylight in Tokyo", Options{Hours: 1, Daylight: &airly.Location{Latitude: 35.6762, Longitude: 139.6503}, Limit: 100}, 13},
+		{"polar night", Options{Hours: 1, Daylight: &airly.Location{Latitude: -80, Longitude: loc.Longitude}}, 0},
+		{"too long", Options{Hours: 25}, 0},
+	}
+	for _, tt := range tests {
+		windows, err := Plan(forecast(), tt.opts)
+		if err != nil {
+			t.Errorf("%s: Plan returned error: %v", tt.name, err)
+			continue
+		}
+		if len(windows) != tt.want {
+			t.Errorf("%s: Plan returned %d windows, want %d", tt.name, len(windows), tt.want)
+		}
+	}
+}
+
+func TestPlan_invalidOptions(t *testing.T) {
+	if _, err := Plan(forecast(), Options{MaxLevel: "GREAT"}); err == nil {
+		t.Error("Plan returned nil error for an unknown level")
+	}
+	if _, err := Plan(forecast(), Options{Hours: -1}); err == nil {
+		t.Error("Plan returned nil error for negative Hours")
+	}
+}
+
+func TestPlan_withoutIndex(t *testing.T) {
+	data := forecast()
+	for i := range data {
+		data[i].Indexes = nil
+	}
+	windows, err := Plan(data, Options{})
+	if err != nil {
+		t.Fatalf("Plan returned error: %v", err)
+	}
+	w := windows[0]
+	if w.Score != 5 || !math.IsNaN(w.MeanIndex) || w.WorstLevel != "" {
+		t.Errorf("Plan returned %+v, want a window ranked by PM2.5", w)
+	}
+}
+
+func TestSunTimes(t *testing.T) {
+	tests := []struct {
+		name            string
+		loc             airly.Location
+		day             time.Time
+		sunrise, sunset time.Time
+	}{
+		{
+			name:    "Warsaw",
+			loc:     airly.Location{Latitude: 52.2297, Longitude: 21.0122},
+			day:     time.Date(2020, 5, 7, 12, 0, 0, 0, time.UTC),
+			sunrise: time.Date(2020, 5, 7, 2, 54, 0, 0, time.UTC),
+			sunset:  time.Date(2020, 5, 7, 18, 12, 0, 0, time.UTC),
+		},
+		{
+			name:    "New York",
+			loc:     airly.Location{Latitude: 40.7128, Longitude: -74.006},
+			day:     time.Date(2020, 5, 7, 12, 0, 0, 0, time.UTC),
+			sunrise: time.Date(2020, 5, 7, 9, 48, 0, 0, time.UTC),
+			sunset:  time.Date(2020, 5, 7, 23, 57, 0, 0, time.UTC),
+		},
+		{
+			// 18:00 PDT on May 7 is already May 8 in UTC.
+			name:    "San Francisco evening",
+			loc:     airly.Location{Latitude: 37.7749, Longitude: -122.4194},
+			day:     time.Date(2020, 5, 8, 1, 0, 0, 0, time.UTC),
+			sunrise: time.Date(2020, 5, 7, 13, 7, 0, 0, time.UTC),
+			sunset:  time.Date(2020, 5, 8, 3, 5, 0, 0, time.UTC),
+		},
+		{
+			// 07:00 JST on May 7 is still May 6 in UTC.
+			name:    "Tokyo morning",
+			loc:     airly.Location{Latitude: 35.6762, Longitude: 139.6503},
+			day:     time.Date(2020, 5, 6, 22, 0, 0, 0, time.UTC),
+			sunrise: time.Date(2020, 5, 6, 19, 43, 0, 0, time.UTC),
+			sunset:  time.Date(2020, 5, 7, 9, 33, 0, 0, time.UTC),
+		},
+		{
+			name:    "Tromsø polar day",
+			loc:     airly.Location{Latitude: 69.65, Longitude: 18.96},
+			day:     time.Date(2020, 6, 21, 0, 0, 0, 0, time.UTC),
+			sunrise: time.Date(2020, 6, 20, 22, 44, 0, 0, time.UTC),
+			sunset:  time.Date(2020, 6, 21, 22, 44, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		sunrise, sunset := SunTimes(tt.loc, tt.day)
+		if d := sunrise.Sub(tt.sunrise); d < -3*time.Minute || d > 3*time.Minute {
+			t.Errorf("%s: sunrise %v, want %v", tt.name, sunrise, tt.sunrise)
+		}
+		if d := sunset.Sub(tt.sunset); d < -3*time.Minute || d > 3*time.Minute {
+			t.Errorf("%s: sunset %v, want %v", tt.name, sunset, tt.sunset)
+		}
+	}
+
+	sunrise, sunset := SunTimes(airly.Location{Latitude: 69.65, Longitude: 18.96}, time.Date(2020, 12, 21, 0, 0, 0, 0, time.UTC))
+	if !sunrise.Equal(sunset) {
+		t.Errorf("polar night: sunrise %v, sunset %v, want equal", sunrise, sunset)
+	}
+}
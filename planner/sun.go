@@ -0,0 +1,70 @@
+package planner
+
+import (
+	"math"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+const (
+	j2000 = 2451545.0
+	// unixEpochJD is the Julian date of the Unix epoch.
+	unixEpochJD = 2440587.5
+	// sunriseAltitude is the altitude of the center of the sun at sunrise,
+	// accounting for refraction and the solar disc.
+	sunriseAltitude = -0.833
+	earthObliquity  = 23.4397
+)
+
+// SunTimes returns the sunrise and sunset at loc on the solar day of day,
+// the calendar day of the local mean time at the longitude of loc. They are
+// computed with the sunrise equation, which is accurate to a few minutes.
+// During polar day, sunrise and sunset are the start and end of the day;
+// during polar night, both are the solar noon.
+func SunTimes(loc airly.Location, day time.Time) (sunrise, sunset time.Time) {
+	offset := time.Duration(loc.Longitude / 15 * float64(time.Hour))
+	local := day.UTC().Add(offset)
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	start := date.Add(-offset)
+	n := math.Round(julianDate(date.Add(12*time.Hour)) - j2000 + 0.0008)
+
+	meanNoon := n - loc.Longitude/360
+	anomaly := math.Mod(357.5291+0.98560028*meanNoon, 360)
+	m := rad(anomaly)
+	center := 1.9148*math.Sin(m) + 0.0200*math.Sin(2*m) + 0.0003*math.Sin(3*m)
+	lambda := rad(math.Mod(anomaly+center+180+102.9372, 360))
+	transit := j2000 + meanNoon + 0.0053*math.Sin(m) - 0.0069*math.Sin(2*lambda)
+
+	sinDecl := math.Sin(lambda) * math.Sin(rad(earthObliquity))
+	cosDecl := math.Cos(math.Asin(sinDecl))
+	lat := rad(loc.Latitude)
+	cosHour := (math.Sin(rad(sunriseAltitude)) - math.Sin(lat)*sinDecl) / (math.Cos(lat) * cosDecl)
+
+	noon := fromJulianDate(transit)
+	switch {
+	case cosHour > 1:
+		return noon, noon
+	case cosHour < -1:
+		return start, start.Add(24 * time.Hour)
+	}
+	hourAngle := deg(math.Acos(cosHour))
+	return fromJulianDate(transit - hourAngle/360), fromJulianDate(transit + hourAngle/360)
+}
+
+func julianDate(t time.Time) float64 {
+	return float64(t.Unix())/86400 + unixEpochJD
+}
+
+func fromJulianDate(jd float64) time.Time {
+	sec := (jd - unixEpochJD) * 86400
+	return time.Unix(0, int64(sec*1e9)).UTC().Round(time.Second)
+}
+
+func rad(d float64) float64 {
+	return d * math.Pi / 180
+}
+
+func deg(r float64) float64 {
+	return r * 180 / math.Pi
+}
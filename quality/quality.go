@@ -0,0 +1,333 @@
+// Package quality finds suspicious values in Airly measurements, such as
+// those of stuck or faulty sensors.
+package quality
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+	"github.com/lsjurczak/go-airly/series"
+)
+
+const (
+	defaultStuckHours        = 6
+	defaultJump              = 100
+	defaultHumidityThreshold = 90
+)
+
+// Flag is a kind of data quality issue.
+type Flag string
+
+const (
+	// Stuck is a value that did not change for Options.StuckHours hours.
+	Stuck Flag = "STUCK"
+	// Implausible is a value outside of its physically plausible range.
+	Implausible Flag = "IMPLAUSIBLE"
+	// Inversion is a PM1 above PM2.5 or a PM2.5 above PM10 concentration.
+	Inversion Flag = "INVERSION"
+	// Jump is a sudden change of a particulate matter concentration
+	// that neighbouring installations did not report.
+	Jump Flag = "JUMP"
+	// HumidityInflation is a particulate matter concentration measured at
+	// high humidity, which optical sensors overestimate.
+	HumidityInflation Flag = "HUMIDITY_INFLATION"
+)
+
+// DefaultRanges are the plausible ranges of hourly values in the units
+// of the Airly API.
+var DefaultRanges = map[string]series.Range{
+	"PM1":          {Min: 0, Max: 1000},
+	"PM25":         {Min: 0, Max: 1000},
+	"PM10":         {Min: 0, Max: 2000},
+	"TEMPERATURE":  {Min: -60, Max: 60},
+	"HUMIDITY":     {Min: 0, Max: 100},
+	"PRESSURE":     {Min: 850, Max: 1090},
+	"WIND_SPEED":   {Min: 0, Max: 250},
+	"WIND_BEARING": {Min: 0, Max: 360},
+}
+
+var particulateMatter = []string{"PM1", "PM25", "PM10"}
+
+// Options configures Check.
+type Options struct {
+	// StuckHours is the number of consecutive hours with the same value
+	// that are flagged as Stuck, 6 if zero.
+	StuckHours int
+	// Ranges are the plausible ranges by value name, DefaultRanges if nil.
+	Ranges map[string]series.Range
+	// Jump is the change of a particulate matter concentration in µg/m³
+	// between consecutive hours flagged as Jump, 100 if zero. A change is
+	// not flagged when the neighbours changed by half as much.
+	Jump float64
+	// HumidityThreshold is the relative humidity in % above which particulate
+	// matter concentrations are flagged as HumidityInflation, 90 if zero.
+	HumidityThreshold float64
+}
+
+// Issue is a suspicious value.
+type Issue struct {
+	// From is the FromDateTime of the data with the value.
+	From   time.Time
+	Name   string
+	Flag   Flag
+	Detail string
+}
+
+// Check returns the issues of the History and Current values of m sorted
+// by time and name. neighbours are measurements of nearby installations,
+// e.g. returned by Neighbours, used to tell sensor faults from changes
+// of the air quality in the area.
+func Check(m airly.Measurement, neighbours []airly.Measurement, opts Options) []Issue {
+	if opts.StuckHours <= 0 {
+		opts.StuckHours = defaultStuckHours
+	}
+	if opts.Ranges == nil {
+		opts.Ranges = DefaultRanges
+	}
+	if opts.Jump <= 0 {
+		opts.Jump = defaultJump
+	}
+	if opts.HumidityThreshold <= 0 {
+		opts.HumidityThreshold = defaultHumidityThreshold
+	}
+
+	data := observed(m)
+	var issues []Issue
+	add := func(d airly.Data, name string, flag Flag, format string, args ...interface{}) {
+		issues = append(issues, Issue{From: d.FromDateTime, Name: name, Flag: flag, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	for i, d := range data {
+		for _, v := range d.Values {
+			if r, ok := opts.Ranges[v.Name]; ok && !r.Contains(v.Value) {
+				add(d, v.Name, Implausible, "%g outside of %g to %g", v.Value, r.Min, r.Max)
+			}
+		}
+
+		pm1, ok1 := d.Value("PM1")
+		pm25, ok25 := d.Value("PM25")
+		pm10, ok10 := d.Value("PM10")
+		if ok1 && ok25 && pm1 > pm25 {
+			add(d, "PM1", Inversion, "PM1 %g above PM2.5 %g", pm1, pm25)
+		}
+		if ok25 && ok10 && pm25 > pm10 {
+			add(d, "PM25", Inversion, "PM2.5 %g above PM10 %g", pm25, pm10)
+		}
+
+		if h, ok := d.Value("HUMIDITY"); ok && h > opts.HumidityThreshold {
+			for _, name := range particulateMatter {
+				if _, ok := d.Value(name); ok {
+					add(d, name, HumidityInflation, "measured at %g%% humidity", h)
+				}
+			}
+		}
+
+		if i == 0 || !d.FromDateTime.Equal(data[i-1].TillDateTime) {
+			continue
+		}
+		for _, name := range particulateMatter {
+			change, ok := delta(data[i-1], d, name)
+			if !ok || math.Abs(change) < opts.Jump {
+				continue
+			}
+			area, ok := neighbourDelta(neighbours, d.FromDateTime, name)
+			if ok && math.Abs(area) >= math.Abs(change)/2 && area*change > 0 {
+				continue
+			}
+			if ok {
+				add(d, name, Jump, "changed by %g, neighbours by %g", change, area)
+			} else {
+				add(d, name, Jump, "changed by %g", change)
+			}
+		}
+	}
+
+	for _, name := range names(data) {
+		run := 1
+		for i := 1; i <= len(data); i++ {
+			if i < len(data) && sameValue(data[i-1], data[i], name) {
+				run++
+				continue
+			}
+			if run >= opts.StuckHours {
+				v, _ := data[i-1].Value(name)
+				for _, d := range data[i-run : i] {
+					add(d, name, Stuck, "%g for %d hours", v, run)
+				}
+			}
+			run = 1
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if !issues[i].From.Equal(issues[j].From) {
+			return issues[i].From.Before(issues[j].From)
+		}
+		return issues[i].Name < issues[j].Name
+	})
+	return issues
+}
+
+// Mode selects how Apply handles flagged values.
+type Mode int
+
+const (
+	// Annotate adds the flags of issues to Data.Flags.
+	Annotate Mode = iota
+	// Filter removes flagged values.
+	Filter
+)
+
+// Apply returns a copy of m with the values of issues annotated or
+// removed according to mode.
+func Apply(m airly.Measurement, issues []Issue, mode Mode) airly.Measurement {
+	type key struct {
+		from time.Time
+		name string
+	}
+	flags := make(map[key][]string)
+	for _, is := range issues {
+		k := key{is.From, is.Name}
+		flags[k] = append(flags[k], string(is.Flag))
+	}
+
+	apply := func(d airly.Data) airly.Data {
+		values := make([]airly.Value, 0, len(d.Values))
+		annotated := make(map[string][]string, len(d.Flags))
+		for name, f := range d.Flags {
+			annotated[name] = append([]string(nil), f...)
+		}
+		for _, v := range d.Values {
+			f := flags[key{d.FromDateTime, v.Name}]
+			if len(f) > 0 && mode == Filter {
+				continue
+			}
+			if len(f) > 0 {
+				annotated[v.Name] = append(annotated[v.Name], f...)
+			}
+			values = append(values, v)
+		}
+		d.Values = values
+		if len(annotated) > 0 {
+			d.Flags = annotated
+		}
+		return d
+	}
+
+	out := m
+	out.Current = apply(m.Current)
+	out.History = make([]airly.Data, len(m.History))
+	for i, d := range m.History {
+		out.History[i] = apply(d)
+	}
+	return out
+}
+
+// Neighbours returns the measurements of up to limit installations within
+// maxDistance km of installation, to be passed to Check. A limit of
+// airly.UnlimitedResults returns all of them.
+func Neighbours(ctx context.Context, installations airly.InstallationAPI, measurements airly.MeasurementAPI,
+	installation airly.Installation, maxDistance float64, limit int) ([]airly.Measurement, error) {
+	if limit == 0 {
+		return nil, nil
+	}
+	// One more result is requested, as installation itself may be among them.
+	results := limit + 1
+	if limit < 0 {
+		results = limit
+	}
+	q := airly.NewNearestInstallationOpts(installation.Location.Latitude, installation.Location.Longitude).
+		MaxDistance(maxDistance).
+		MaxResults(results)
+	nearest, err := installations.Nearest(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	var out []airly.Measurement
+	for _, n := range nearest {
+		if n.ID == installation.ID || limit > 0 && len(out) == limit {
+			continue
+		}
+		m, err := measurements.ByID(ctx, airly.NewByIDMeasurementOpts(n.ID))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// observed returns the History and Current of m sorted by time.
+func observed(m airly.Measurement) []airly.Data {
+	data := append([]airly.Data(nil), m.History...)
+	if !m.Current.FromDateTime.IsZero() {
+		data = append(data, m.Current)
+	}
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].FromDateTime.Before(data[j].FromDateTime)
+	})
+	return data
+}
+
+func names(data []airly.Data) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, d := range data {
+		for _, v := range d.Values {
+			if !seen[v.Name] {
+				seen[v.Name] = true
+				out = append(out, v.Name)
+			}
+		}
+	}
+	return out
+}
+
+func sameValue(a, b airly.Data, name string) bool {
+	va, okA := a.Value(name)
+	vb, okB := b.Value(name)
+	return okA && okB && va == vb && b.FromDateTime.Equal(a.TillDateTime)
+}
+
+func delta(prev, d airly.Data, name string) (float64, bool) {
+	a, okA := prev.Value(name)
+	b, okB := d.Value(name)
+	return b - a, okA && okB
+}
+
+// neighbourDelta returns the median change of the value of neighbours
+// in the hour starting at from.
+func neighbourDelta(neighbours []airly.Measurement, from time.Time, name string) (float64, bool) {
+	var changes []float64
+	for _, n := range neighbours {
+		var prev, cur *airly.Data
+		for _, d := range observed(n) {
+			d := d
+			switch {
+			case d.FromDateTime.Equal(from):
+				cur = &d
+			case d.TillDateTime.Equal(from):
+				prev = &d
+			}
+		}
+		if prev == nil || cur == nil {
+			continue
+		}
+		if change, ok := delta(*prev, *cur, name); ok {
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return 0, false
+	}
+	sort.Float64s(changes)
+	mid := len(changes) / 2
+	if len(changes)%2 == 0 {
+		return (changes[mid-1] + changes[mid]) / 2, true
+	}
+	return changes[mid], true
+}
@@ -0,0 +1,195 @@
+package quality
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+var epoch = time.Date(2020, 5, 7, 0, 0, 0, 0, time.UTC)
+
+type hour map[string]float64
+
+// measurement returns a measurement with an hour of history per element
+// of hours, the last one is Current.
+func measurement(hours ...hour) airly.Measurement {
+	var data []airly.Data
+	for i, h := range hours {
+		d := airly.Data{
+			FromDateTime: epoch.Add(time.Duration(i) * time.Hour),
+			TillDateTime: epoch.Add(time.Duration(i+1) * time.Hour),
+		}
+		for _, name := range []string{"PM1", "PM25", "PM10", "HUMIDITY", "TEMPERATURE"} {
+			if v, ok := h[name]; ok {
+				d.Values = append(d.Values, airly.Value{Name: name, Value: v})
+			}
+		}
+		data = append(data, d)
+	}
+	return airly.Measurement{History: data[:len(data)-1], Current: data[len(data)-1]}
+}
+
+func at(h int) time.Time {
+	return epoch.Add(time.Duration(h) * time.Hour)
+}
+
+type found struct {
+	From time.Time
+	Name string
+	Flag Flag
+}
+
+func flags(issues []Issue) []found {
+	var out []found
+	for _, is := range issues {
+		out = append(out, found{is.From, is.Name, is.Flag})
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	m := measurement(
+		hour{"PM1": 5, "PM25": 10, "PM10": 15, "HUMIDITY": 60},
+		hour{"PM1": 12, "PM25": 10, "PM10": 8, "HUMIDITY": 60},
+		hour{"PM1": 5, "PM25": 3000, "PM10": 3500, "HUMIDITY": 60},
+		hour{"PM1": 5, "PM25": 12, "PM10": 16, "HUMIDITY": 95},
+	)
+
+	got := flags(Check(m, nil, Options{}))
+	want := []found{
+		{at(1), "PM1", Inversion},
+		{at(1), "PM25", Inversion},
+		{at(2), "PM10", Implausible},
+		{at(2), "PM10", Jump},
+		{at(2), "PM25", Implausible},
+		{at(2), "PM25", Jump},
+		{at(3), "PM1", HumidityInflation},
+		{at(3), "PM10", HumidityInflation},
+		{at(3), "PM10", Jump},
+		{at(3), "PM25", HumidityInflation},
+		{at(3), "PM25", Jump},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check returned %+v, want %+v", got, want)
+	}
+}
+
+func TestCheck_stuck(t *testing.T) {
+	var hours []hour
+	for i := 0; i < 8; i++ {
+		hours = append(hours, hour{"PM25": 7, "TEMPERATURE": float64(i)})
+	}
+	hours[0]["PM25"] = 8
+
+	got := flags(Check(measurement(hours...), nil, Options{StuckHours: 6}))
+	if len(got) != 7 {
+		t.Fatalf("Check returned %+v, want 7 stuck hours", got)
+	}
+	for i, f := range got {
+		if f != (found{at(i + 1), "PM25", Stuck}) {
+			t.Errorf("issue %d is %+v, want PM25 stuck at %v", i, f, at(i+1))
+		}
+	}
+}
+
+func TestCheck_jumpWithNeighbours(t *testing.T) {
+	m := measurement(hour{"PM25": 20}, hour{"PM25": 150})
+	regional := []airly.Measurement{
+		measurement(hour{"PM25": 25}, hour{"PM25": 140}),
+		measurement(hour{"PM25": 18}, hour{"PM25": 120}),
+	}
+	local := []airly.Measurement{
+		measurement(hour{"PM25": 25}, hour{"PM25": 27}),
+	}
+
+	if got := Check(m, regional, Options{}); len(got) != 0 {
+		t.Errorf("Check returned %+v for a regional change, want none", got)
+	}
+	got := flags(Check(m, local, Options{}))
+	want := []found{{at(1), "PM25", Jump}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check returned %+v, want %+v", got, want)
+	}
+}
+
+func TestApply(t *testing.T) {
+	m := measurement(
+		hour{"PM25": 10, "PM10": 15},
+		hour{"PM25": 20, "PM10": 18},
+	)
+	issues := Check(m, nil, Options{})
+
+	annotated := Apply(m, issues, Annotate)
+	wantFlags := map[string][]string{"PM25": {"INVERSION"}}
+	if !reflect.DeepEqual(annotated.Current.Flags, wantFlags) {
+		t.Errorf("Apply(Annotate) flagged %+v, want %+v", annotated.Current.Flags, wantFlags)
+	}
+	if !reflect.DeepEqual(annotated.Current.Values, m.Current.Values) {
+		t.Errorf("Apply(Annotate) returned %+v, want %+v", annotated.Current.Values, m.Current.Values)
+	}
+	if annotated.History[0].Flags != nil {
+		t.Errorf("Apply(Annotate) flagged %+v, want none", annotated.History[0].Flags)
+	}
+
+	filtered := Apply(m, issues, Filter)
+	want := []airly.Value{{Name: "PM10", Value: 18}}
+	if !reflect.DeepEqual(filtered.Current.Values, want) {
+		t.Errorf("Apply(Filter) returned %+v, want %+v", filtered.Current.Values, want)
+	}
+	if m.Current.Flags != nil || len(m.Current.Values) != 2 {
+		t.Errorf("Apply modified the measurement: %+v", m.Current)
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	self := airly.Installation{ID: 1, Location: airly.Location{Latitude: 50.06, Longitude: 19.94}}
+	installations := &airly.FakeInstallationService{
+		NearestFunc: func(ctx context.Context, opts *airly.NearestInstallationOpts) ([]airly.Installation, error) {
+			return []airly.Installation{self, {ID: 2}, {ID: 3}, {ID: 4}}, nil
+		},
+	}
+	measurements := &airly.FakeMeasurementService{}
+
+	got, err := Neighbours(context.Background(), installations, measurements, self, 2, 2)
+	if err != nil {
+		t.Fatalf("Neighbours returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("Neighbours returned %d measurements, want 2", len(got))
+	}
+	want := []airly.Call{
+		{Method: "ByID", Args: []interface{}{airly.NewByIDMeasurementOpts(2)}},
+		{Method: "ByID", Args: []interface{}{airly.NewByIDMeasurementOpts(3)}},
+	}
+	if calls := measurements.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("measurements called with %+v, want %+v", calls, want)
+	}
+}
+
+func TestNeighbours_limits(t *testing.T) {
+	self := airly.Installation{ID: 1, Location: airly.Location{Latitude: 50.06, Longitude: 19.94}}
+	installations := &airly.FakeInstallationService{
+		NearestFunc: func(ctx context.Context, opts *airly.NearestInstallationOpts) ([]airly.Installation, error) {
+			return []airly.Installation{self, {ID: 2}, {ID: 3}, {ID: 4}}, nil
+		},
+	}
+
+	got, err := Neighbours(context.Background(), installations, &airly.FakeMeasurementService{}, self, 2, airly.UnlimitedResults)
+	if err != nil {
+		t.Fatalf("Neighbours returned error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("Neighbours returned %d measurements, want 3", len(got))
+	}
+
+	got, err = Neighbours(context.Background(), installations, &airly.FakeMeasurementService{}, self, 2, 0)
+	if err != nil || len(got) != 0 {
+		t.Errorf("Neighbours with limit 0 returned %v, %v, want none", got, err)
+	}
+	if n := len(installations.Calls()); n != 1 {
+		t.Errorf("made %d Nearest calls, want 1", n)
+	}
+}
@@ -0,0 +1,85 @@
+package airly
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Rate is a rate limit of the api key. Fields are -1 when the response
+// did not include them.
+type Rate struct {
+	Limit     int
+	Remaining int
+}
+
+// RateLimits holds the daily and per minute rate limits of the api key.
+type RateLimits struct {
+	Day    Rate
+	Minute Rate
+}
+
+// Response holds metadata of the API response a result was decoded from.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	RateLimits RateLimits
+	// Date is the time the response was generated by the server.
+	Date time.Time
+	ETag string
+	// RequestID is the X-Request-Id header, if any.
+	RequestID string
+	// Latency is the time it took to receive the response,
+	// zero for cached responses.
+	Latency time.Duration
+	// Cached reports whether the result came from the client's cache.
+	Cached bool
+	// Embedded reports whether the result came from the meta snapshot
+	// embedded in the package, see EmbeddedMeta.
+	Embedded bool
+
+	body []byte
+}
+
+// Body returns the raw response body. It must not be modified.
+func (r *Response) Body() []byte {
+	return r.body
+}
+
+func newResponse(resp *http.Response, body []byte, latency time.Duration) *Response {
+	r := responseFromHeader(resp.StatusCode, resp.Header, body)
+	r.Latency = latency
+	return r
+}
+
+func responseFromHeader(status int, header http.Header, body []byte) *Response {
+	r := &Response{
+		StatusCode: status,
+		Header:     header,
+		RateLimits: RateLimits{
+			Day: Rate{
+				Limit:     headerInt(header, "X-RateLimit-Limit-day"),
+				Remaining: headerInt(header, "X-RateLimit-Remaining-day"),
+			},
+			Minute: Rate{
+				Limit:     headerInt(header, "X-RateLimit-Limit-minute"),
+				Remaining: headerInt(header, "X-RateLimit-Remaining-minute"),
+			},
+		},
+		ETag:      header.Get("ETag"),
+		RequestID: header.Get("X-Request-Id"),
+		body:      body,
+	}
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		r.Date = date
+	}
+	return r
+}
+
+func headerInt(header http.Header, key string) int {
+	n, err := strconv.Atoi(header.Get(key))
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,64 @@
+package airly
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMeasurementService_ByIDWithResponse(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/measurements/installation", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", "Thu, 07 May 2020 15:10:00 GMT")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-Request-Id", "req-1")
+		w.Header().Set("X-RateLimit-Limit-day", "100")
+		w.Header().Set("X-RateLimit-Remaining-day", "98")
+		fmt.Fprint(w, mockMeasurementResponse)
+	})
+	client.now = func() time.Time { return time.Date(2020, 5, 7, 15, 10, 0, 0, time.UTC) }
+	client.Cache(NewMemoryCache(10, 0), CacheOptions{})
+
+	_, resp, err := client.Measurement.ByIDWithResponse(context.Background(), NewByIDMeasurementOpts(6600))
+	if err != nil {
+		t.Fatalf("Measurement.ByIDWithResponse: %v", err)
+	}
+	wantDate := time.Date(2020, 5, 7, 15, 10, 0, 0, time.UTC)
+	if resp.StatusCode != http.StatusOK || resp.Cached || !resp.Date.Equal(wantDate) ||
+		resp.ETag != `"v1"` || resp.RequestID != "req-1" || string(resp.Body()) != mockMeasurementResponse {
+		t.Errorf("response %+v", resp)
+	}
+	if want := (RateLimits{Day: Rate{100, 98}, Minute: Rate{-1, -1}}); resp.RateLimits != want {
+		t.Errorf("rate limits %+v, want %+v", resp.RateLimits, want)
+	}
+
+	_, resp, err = client.Measurement.ByIDWithResponse(context.Background(), NewByIDMeasurementOpts(6600))
+	if err != nil {
+		t.Fatalf("Measurement.ByIDWithResponse: %v", err)
+	}
+	if !resp.Cached || resp.ETag != `"v1"` || resp.Latency != 0 {
+		t.Errorf("cached response %+v", resp)
+	}
+}
+
+func TestInstallationService_ByIDWithResponse_error(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/installations/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errorCode":"INSTALLATION_NOT_FOUND","message":"Not found"}`)
+	})
+
+	_, resp, err := client.Installation.ByIDWithResponse(context.Background(), 1)
+	if err == nil {
+		t.Fatal("Installation.ByIDWithResponse succeeded")
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("response %+v, want status 404", resp)
+	}
+}
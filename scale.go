@@ -0,0 +1,186 @@
+package airly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Palette is a list of "#RRGGBB" colors from the best to the worst level.
+type Palette []string
+
+// Color-blind-safe sequential palettes. Levels get colors evenly
+// interpolated between the palette colors.
+var (
+	// PaletteViridis is the viridis palette of matplotlib.
+	PaletteViridis = Palette{"#440154", "#3B528B", "#21918C", "#5EC962", "#FDE725"}
+	// PaletteCividis is optimized for color vision deficiency,
+	// https://doi.org/10.1371/journal.pone.0199239.
+	PaletteCividis = Palette{"#00204D", "#414D6B", "#7C7B78", "#BCAF6F", "#FFEA46"}
+)
+
+// Reverse returns the palette in reverse order.
+func (p Palette) Reverse() Palette {
+	out := make(Palette, len(p))
+	for i, c := range p {
+		out[len(p)-1-i] = c
+	}
+	return out
+}
+
+// IndexScale maps values of an index to its levels and colors.
+type IndexScale struct {
+	name   string
+	levels []Level
+	colors []rgb
+}
+
+// NewIndexScale creates the scale of the index named name, e.g.
+// "AIRLY_CAQI", from indexes returned by MetaService.Indexes.
+func NewIndexScale(indexes []IndexType, name string) (*IndexScale, error) {
+	for _, idx := range indexes {
+		if idx.Name != name {
+			continue
+		}
+		if len(idx.Levels) == 0 {
+			return nil, fmt.Errorf("index %s has no levels", name)
+		}
+		levels := append([]Level(nil), idx.Levels...)
+		sort.SliceStable(levels, func(i, j int) bool {
+			return levels[i].MinValue < levels[j].MinValue
+		})
+		s := &IndexScale{name: name, levels: levels}
+		for _, l := range levels {
+			c, err := parseColor(l.Color)
+			if err != nil {
+				return nil, fmt.Errorf("level %s: %w", l.Level, err)
+			}
+			s.colors = append(s.colors, c)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown index %s", name)
+}
+
+// Name returns the name of the index.
+func (s *IndexScale) Name() string {
+	return s.name
+}
+
+// Levels returns the levels sorted by MinValue.
+func (s *IndexScale) Levels() []Level {
+	return append([]Level(nil), s.levels...)
+}
+
+// Classify returns the level of value. The last level is open-ended, its
+// MaxValue is zero in API responses. Values below the first level are not
+// classified.
+func (s *IndexScale) Classify(value float64) (Level, bool) {
+	i := s.levelIndex(value)
+	if i < 0 {
+		return Level{}, false
+	}
+	return s.levels[i], true
+}
+
+func (s *IndexScale) levelIndex(value float64) int {
+	if math.IsNaN(value) || value < s.levels[0].MinValue {
+		return -1
+	}
+	for i := len(s.levels) - 1; i >= 0; i-- {
+		if value >= s.levels[i].MinValue {
+			return i
+		}
+	}
+	return -1
+}
+
+// Color returns the color of value interpolated between the colors of
+// levels, e.g. to draw gradients. Colors are anchored at the middle of
+// every level, and at the MinValue of the open-ended last level.
+func (s *IndexScale) Color(value float64) string {
+	if math.IsNaN(value) {
+		return ""
+	}
+	anchors := make([]float64, len(s.levels))
+	for i, l := range s.levels {
+		anchors[i] = l.MinValue
+		if l.MaxValue > l.MinValue && i < len(s.levels)-1 {
+			anchors[i] = (l.MinValue + l.MaxValue) / 2
+		}
+	}
+	if value <= anchors[0] {
+		return s.colors[0].String()
+	}
+	for i := 1; i < len(anchors); i++ {
+		if value <= anchors[i] {
+			f := (value - anchors[i-1]) / (anchors[i] - anchors[i-1])
+			return s.colors[i-1].mix(s.colors[i], f).String()
+		}
+	}
+	return s.colors[len(s.colors)-1].String()
+}
+
+// WithPalette returns a copy of the scale with the level colors replaced
+// by colors evenly interpolated from p.
+func (s *IndexScale) WithPalette(p Palette) (*IndexScale, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("empty palette")
+	}
+	stops := make([]rgb, len(p))
+	for i, c := range p {
+		var err error
+		if stops[i], err = parseColor(c); err != nil {
+			return nil, err
+		}
+	}
+	out := &IndexScale{
+		name:   s.name,
+		levels: append([]Level(nil), s.levels...),
+		colors: make([]rgb, len(s.levels)),
+	}
+	for i := range out.levels {
+		var c rgb
+		if len(stops) == 1 || len(out.levels) == 1 {
+			c = stops[0]
+		} else {
+			pos := float64(i) / float64(len(out.levels)-1) * float64(len(stops)-1)
+			lo := int(math.Floor(pos))
+			if lo == len(stops)-1 {
+				lo--
+			}
+			c = stops[lo].mix(stops[lo+1], pos-float64(lo))
+		}
+		out.colors[i] = c
+		out.levels[i].Color = c.String()
+	}
+	return out, nil
+}
+
+type rgb struct {
+	r, g, b float64
+}
+
+func parseColor(s string) (rgb, error) {
+	if len(s) != 7 || s[0] != '#' {
+		return rgb{}, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return rgb{}, fmt.Errorf("invalid color %q", s)
+	}
+	return rgb{float64(v >> 16 & 0xff), float64(v >> 8 & 0xff), float64(v & 0xff)}, nil
+}
+
+func (c rgb) mix(o rgb, f float64) rgb {
+	return rgb{
+		r: c.r + (o.r-c.r)*f,
+		g: c.g + (o.g-c.g)*f,
+		b: c.b + (o.b-c.b)*f,
+	}
+}
+
+func (c rgb) String() string {
+	return fmt.Sprintf("#%02X%02X%02X", int(math.Round(c.r)), int(math.Round(c.g)), int(math.Round(c.b)))
+}
@@ -0,0 +1,97 @@
+package airly
+
+import (
+	"math"
+	"testing"
+)
+
+func newTestScale(t *testing.T, name string) *IndexScale {
+	t.Helper()
+	s, err := NewIndexScale(EmbeddedMeta().Languages["en"].Indexes, name)
+	if err != nil {
+		t.Fatalf("NewIndexScale: %v", err)
+	}
+	return s
+}
+
+func TestIndexScale_Classify(t *testing.T) {
+	s := newTestScale(t, "AIRLY_CAQI")
+	tests := []struct {
+		value float64
+		level string
+	}{
+		{0, "VERY_LOW"},
+		{24.9, "VERY_LOW"},
+		{25, "LOW"},
+		{87.5, "VERY_HIGH"},
+		{124, "EXTREME"},
+		{500, "AIRMAGEDDON"},
+	}
+	for _, tt := range tests {
+		l, ok := s.Classify(tt.value)
+		if !ok || l.Level != tt.level {
+			t.Errorf("Classify(%v) returned %+v, %v, want %s", tt.value, l, ok, tt.level)
+		}
+	}
+	for _, v := range []float64{-1, math.NaN()} {
+		if l, ok := s.Classify(v); ok {
+			t.Errorf("Classify(%v) returned %+v", v, l)
+		}
+	}
+}
+
+func TestIndexScale_Color(t *testing.T) {
+	s := newTestScale(t, "AIRLY_CAQI")
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{-5, "#6BC926"},
+		{12.5, "#6BC926"},
+		{25, "#9ECC22"},
+		{37.5, "#D1CF1E"},
+		{1000, "#770078"},
+	}
+	for _, tt := range tests {
+		if got := s.Color(tt.value); got != tt.want {
+			t.Errorf("Color(%v) returned %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIndexScale_WithPalette(t *testing.T) {
+	s, err := newTestScale(t, "CAQI").WithPalette(PaletteViridis)
+	if err != nil {
+		t.Fatalf("WithPalette returned error: %v", err)
+	}
+	for i, l := range s.Levels() {
+		if l.Color != PaletteViridis[i] {
+			t.Errorf("level %s has color %s, want %s", l.Level, l.Color, PaletteViridis[i])
+		}
+	}
+	if got, want := s.Color(0), PaletteViridis[0]; got != want {
+		t.Errorf("Color(0) returned %s, want %s", got, want)
+	}
+
+	s, err = newTestScale(t, "AIRLY_CAQI").WithPalette(PaletteCividis.Reverse())
+	if err != nil {
+		t.Fatalf("WithPalette returned error: %v", err)
+	}
+	levels := s.Levels()
+	if levels[0].Color != "#FFEA46" || levels[len(levels)-1].Color != "#00204D" {
+		t.Errorf("WithPalette returned levels %+v", levels)
+	}
+	if original := newTestScale(t, "AIRLY_CAQI").Levels()[0].Color; original != "#6BC926" {
+		t.Errorf("WithPalette modified the scale: %s", original)
+	}
+}
+
+func TestNewIndexScale_invalid(t *testing.T) {
+	if _, err := NewIndexScale(EmbeddedMeta().Languages["en"].Indexes, "AQI"); err == nil {
+		t.Error("NewIndexScale returned nil error for an unknown index")
+	}
+	indexes := []IndexType{{Name: "X", Levels: []Level{{Level: "LOW", Color: "green"}}}}
+	if _, err := NewIndexScale(indexes, "X"); err == nil {
+		t.Error("NewIndexScale returned nil error for an invalid color")
+	}
+}
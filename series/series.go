@@ -0,0 +1,228 @@
+// Package series provides analytics of Airly measurement time series,
+// such as Measurement.History and Measurement.Forecast.
+package series
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+// Point is a value averaged over the period from From till Till.
+type Point struct {
+	From  time.Time
+	Till  time.Time
+	Value float64
+}
+
+// Series is a time series of points sorted by From.
+//
+// Statistics of an empty Series are NaN.
+type Series []Point
+
+// Extract returns the series of the value named name, e.g. "PM25",
+// from data. Data points without the value are skipped.
+func Extract(data []airly.Data, name string) Series {
+	var s Series
+	for _, d := range data {
+		if v, ok := d.Value(name); ok {
+			s = append(s, Point{From: d.FromDateTime, Till: d.TillDateTime, Value: v})
+		}
+	}
+	sortByFrom(s)
+	return s
+}
+
+// ExtractIndex returns the series of the index named name,
+// e.g. "AIRLY_CAQI", from data.
+func ExtractIndex(data []airly.Data, name string) Series {
+	var s Series
+	for _, d := range data {
+		for _, idx := range d.Indexes {
+			if idx.Name == name {
+				s = append(s, Point{From: d.FromDateTime, Till: d.TillDateTime, Value: idx.Value})
+				break
+			}
+		}
+	}
+	sortByFrom(s)
+	return s
+}
+
+func sortByFrom(s Series) {
+	sort.SliceStable(s, func(i, j int) bool {
+		return s[i].From.Before(s[j].From)
+	})
+}
+
+// Values returns the values of the series.
+func (s Series) Values() []float64 {
+	values := make([]float64, len(s))
+	for i, p := range s {
+		values[i] = p.Value
+	}
+	return values
+}
+
+// Stats summarizes the values of a series.
+type Stats struct {
+	Count  int
+	Min    float64
+	Max    float64
+	Mean   float64
+	Median float64
+}
+
+// Stats returns the summary of the values of s.
+func (s Series) Stats() Stats {
+	st := Stats{
+		Count:  len(s),
+		Min:    math.NaN(),
+		Max:    math.NaN(),
+		Mean:   s.Mean(),
+		Median: s.Percentile(50),
+	}
+	for i, p := range s {
+		if i == 0 || p.Value < st.Min {
+			st.Min = p.Value
+		}
+		if i == 0 || p.Value > st.Max {
+			st.Max = p.Value
+		}
+	}
+	return st
+}
+
+// Mean returns the arithmetic mean of the values of s.
+func (s Series) Mean() float64 {
+	if len(s) == 0 {
+		return math.NaN()
+	}
+	var sum float64
+	for _, p := range s {
+		sum += p.Value
+	}
+	return sum / float64(len(s))
+}
+
+// Percentile returns the p-th percentile, 0 <= p <= 100, of the values
+// of s, interpolating linearly between the closest ranks.
+func (s Series) Percentile(p float64) float64 {
+	if len(s) == 0 || p < 0 || p > 100 || math.IsNaN(p) {
+		return math.NaN()
+	}
+	values := s.Values()
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return values[lo] + (values[hi]-values[lo])*(rank-float64(lo))
+}
+
+// Rolling returns the moving average of s over window, e.g. 8 hours:
+// every point of the result is the mean of the points of s that started
+// within window before its Till. Missing points are left out of the mean.
+func (s Series) Rolling(window time.Duration) Series {
+	out := make(Series, len(s))
+	start := 0
+	var sum float64
+	for i, p := range s {
+		sum += p.Value
+		for start < i && s[start].From.Before(p.Till.Add(-window)) {
+			sum -= s[start].Value
+			start++
+		}
+		out[i] = Point{
+			From:  p.Till.Add(-window),
+			Till:  p.Till,
+			Value: sum / float64(i-start+1),
+		}
+	}
+	return out
+}
+
+// DailyMean is the mean of the points of a single day.
+type DailyMean struct {
+	// Date is the midnight starting the day.
+	Date  time.Time
+	Mean  float64
+	Count int
+}
+
+// DailyMeans returns the mean of every day of s in loc, e.g. the
+// installation's time zone. Points belong to the day they start in.
+func (s Series) DailyMeans(loc *time.Location) []DailyMean {
+	var days []DailyMean
+	for _, p := range s {
+		from := p.From.In(loc)
+		date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+		if n := len(days); n == 0 || !days[n-1].Date.Equal(date) {
+			days = append(days, DailyMean{Date: date})
+		}
+		d := &days[len(days)-1]
+		d.Mean += p.Value
+		d.Count++
+	}
+	for i := range days {
+		days[i].Mean /= float64(days[i].Count)
+	}
+	return days
+}
+
+// Slope returns the trend of s in value units per hour,
+// fitted with the least squares method.
+func (s Series) Slope() float64 {
+	if len(s) < 2 {
+		return math.NaN()
+	}
+	n := float64(len(s))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range s {
+		x := p.From.Sub(s[0].From).Hours()
+		sumX += x
+		sumY += p.Value
+		sumXY += x * p.Value
+		sumXX += x * x
+	}
+	d := n*sumXX - sumX*sumX
+	if d == 0 {
+		return math.NaN()
+	}
+	return (n*sumXY - sumX*sumY) / d
+}
+
+// Range is an inclusive range of values.
+type Range struct {
+	Min float64
+	Max float64
+}
+
+// Contains reports whether v is within r, never for NaN.
+func (r Range) Contains(v float64) bool {
+	return v >= r.Min && v <= r.Max
+}
+
+// Gap is a period without points.
+type Gap struct {
+	From time.Time
+	Till time.Time
+}
+
+// Duration returns the length of the gap.
+func (g Gap) Duration() time.Duration {
+	return g.Till.Sub(g.From)
+}
+
+// Gaps returns the periods between consecutive points of s
+// that are not covered by any point, e.g. missing hours.
+func (s Series) Gaps() []Gap {
+	var gaps []Gap
+	for i := 1; i < len(s); i++ {
+		if s[i].From.After(s[i-1].Till) {
+			gaps = append(gaps, Gap{From: s[i-1].Till, Till: s[i].From})
+		}
+	}
+	return gaps
+}
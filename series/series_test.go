@@ -0,0 +1,154 @@
+package series
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+var epoch = time.Date(2020, 5, 7, 0, 0, 0, 0, time.UTC)
+
+// hourly returns a series of consecutive hours starting at epoch.
+func hourly(values ...float64) Series {
+	s := make(Series, len(values))
+	for i, v := range values {
+		from := epoch.Add(time.Duration(i) * time.Hour)
+		s[i] = Point{From: from, Till: from.Add(time.Hour), Value: v}
+	}
+	return s
+}
+
+func TestExtract(t *testing.T) {
+	data := []airly.Data{
+		{
+			FromDateTime: epoch.Add(time.Hour),
+			TillDateTime: epoch.Add(2 * time.Hour),
+			Values:       []airly.Value{{Name: "PM10", Value: 20}, {Name: "PM25", Value: 12}},
+			Indexes:      []airly.Index{{Name: "AIRLY_CAQI", Value: 21}},
+		},
+		{
+			FromDateTime: epoch,
+			TillDateTime: epoch.Add(time.Hour),
+			Values:       []airly.Value{{Name: "PM25", Value: 10}},
+			Indexes:      []airly.Index{{Name: "AIRLY_CAQI", Value: 17}},
+		},
+		{
+			FromDateTime: epoch.Add(2 * time.Hour),
+			TillDateTime: epoch.Add(3 * time.Hour),
+			Values:       []airly.Value{{Name: "PM10", Value: 30}},
+		},
+	}
+
+	if got, want := Extract(data, "PM25"), hourly(10, 12); !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract returned %+v, want %+v", got, want)
+	}
+	if got, want := ExtractIndex(data, "AIRLY_CAQI"), hourly(17, 21); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractIndex returned %+v, want %+v", got, want)
+	}
+}
+
+func TestSeries_Stats(t *testing.T) {
+	got := hourly(4, 1, 3, 2, 10).Stats()
+	want := Stats{Count: 5, Min: 1, Max: 10, Mean: 4, Median: 3}
+	if got != want {
+		t.Errorf("Stats returned %+v, want %+v", got, want)
+	}
+
+	empty := Series(nil).Stats()
+	if empty.Count != 0 || !math.IsNaN(empty.Min) || !math.IsNaN(empty.Mean) || !math.IsNaN(empty.Median) {
+		t.Errorf("Stats of empty series returned %+v, want NaN", empty)
+	}
+}
+
+func TestSeries_Percentile(t *testing.T) {
+	s := hourly(15, 20, 35, 40, 50)
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 15},
+		{25, 20},
+		{40, 29},
+		{90, 46},
+		{100, 50},
+	}
+	for _, tt := range tests {
+		if got := s.Percentile(tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Percentile(%v) returned %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := s.Percentile(101); !math.IsNaN(got) {
+		t.Errorf("Percentile(101) returned %v, want NaN", got)
+	}
+}
+
+func TestSeries_Rolling(t *testing.T) {
+	s := hourly(1, 2, 3, 4, 5)
+	// The fourth hour is missing.
+	s = append(s[:3], s[4])
+
+	got := s.Rolling(3 * time.Hour).Values()
+	want := []float64{1, 1.5, 2, 4}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Rolling returned %v, want %v", got, want)
+	}
+	if got := s.Rolling(time.Hour).Values(); !reflect.DeepEqual(got, s.Values()) {
+		t.Errorf("Rolling(1h) returned %v, want %v", got, s.Values())
+	}
+}
+
+func TestSeries_DailyMeans(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skipf("time zone database: %v", err)
+	}
+	// 00:00-01:00 UTC is 02:00-03:00 in Warsaw, 22:00-23:00 UTC the next day.
+	s := hourly(make([]float64, 24)...)
+	for i := range s {
+		s[i].Value = float64(i)
+	}
+
+	got := s.DailyMeans(warsaw)
+	want := []DailyMean{
+		{Date: time.Date(2020, 5, 7, 0, 0, 0, 0, warsaw), Mean: 10.5, Count: 22},
+		{Date: time.Date(2020, 5, 8, 0, 0, 0, 0, warsaw), Mean: 22.5, Count: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DailyMeans returned %+v, want %+v", got, want)
+	}
+}
+
+func TestSeries_Slope(t *testing.T) {
+	if got := hourly(10, 12, 14, 16).Slope(); math.Abs(got-2) > 1e-9 {
+		t.Errorf("Slope returned %v, want 2", got)
+	}
+	if got := hourly(10).Slope(); !math.IsNaN(got) {
+		t.Errorf("Slope of a single point returned %v, want NaN", got)
+	}
+}
+
+func TestSeries_Gaps(t *testing.T) {
+	s := hourly(1, 2, 3, 4, 5, 6)
+	s = append(Series{s[0]}, s[3:]...)
+
+	got := s.Gaps()
+	want := []Gap{{From: epoch.Add(time.Hour), Till: epoch.Add(3 * time.Hour)}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Gaps returned %+v, want %+v", got, want)
+	}
+	if d := got[0].Duration(); d != 2*time.Hour {
+		t.Errorf("Duration returned %v, want 2h", d)
+	}
+}
+
+func TestRange_Contains(t *testing.T) {
+	r := Range{Min: -10, Max: 30}
+	for v, want := range map[float64]bool{-10: true, 30: true, 12.5: true, -10.1: false, 31: false, math.NaN(): false} {
+		if got := r.Contains(v); got != want {
+			t.Errorf("Contains(%v) = %v, want %v", v, got, want)
+		}
+	}
+}
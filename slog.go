@@ -0,0 +1,121 @@
+package airly
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+const defaultBodyLogLimit = 2048
+
+// redacted replaces secrets in logs and dumps.
+const redacted = "REDACTED"
+
+// SlogOptions configures StructuredLoggingMiddleware.
+type SlogOptions struct {
+	// SensitiveParams are query params whose values are redacted.
+	// The apiKey header and query param are always redacted.
+	SensitiveParams []string
+	// BodyLimit is the number of body bytes logged at debug level,
+	// 2048 if zero.
+	BodyLimit int
+}
+
+// StructuredLoggingMiddleware logs every request to logger with its method,
+// path, query, status, latency, response size, remaining daily rate limit
+// and the Airly error code of failed requests. At debug level it also logs
+// request headers and the truncated response body.
+func StructuredLoggingMiddleware(logger *slog.Logger, opts SlogOptions) Middleware {
+	if opts.BodyLimit <= 0 {
+		opts.BodyLimit = defaultBodyLogLimit
+	}
+	return func(next HTTPDoer) HTTPDoer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			start := time.Now()
+			resp, err := next.Do(req)
+			latency := time.Since(start)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.String("query", redactQuery(req, opts.SensitiveParams)),
+				slog.Duration("latency", latency),
+			}
+			debug := logger.Enabled(ctx, slog.LevelDebug)
+			if debug {
+				attrs = append(attrs, slog.Any("header", redactHeader(req.Header)))
+			}
+
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+				logger.LogAttrs(ctx, slog.LevelError, "airly request failed", attrs...)
+				return resp, err
+			}
+
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			if readErr != nil {
+				attrs = append(attrs, slog.String("error", readErr.Error()))
+				logger.LogAttrs(ctx, slog.LevelError, "airly request failed", attrs...)
+				return nil, readErr
+			}
+
+			attrs = append(attrs,
+				slog.Int("status", resp.StatusCode),
+				slog.Int("bytes", len(body)),
+			)
+			if remaining := resp.Header.Get("X-RateLimit-Remaining-day"); remaining != "" {
+				attrs = append(attrs, slog.String("rateLimitRemaining", remaining))
+			}
+
+			// A 304 answers a conditional request with the stored response.
+			level := slog.LevelInfo
+			if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotModified {
+				level = slog.LevelWarn
+				var e Error
+				if json.Unmarshal(body, &e) == nil && e.ErrorCode != "" {
+					attrs = append(attrs, slog.String("errorCode", e.ErrorCode))
+				}
+			}
+			if debug {
+				attrs = append(attrs, slog.String("body", truncate(body, opts.BodyLimit)))
+			}
+			logger.LogAttrs(ctx, level, "airly request", attrs...)
+			return resp, nil
+		})
+	}
+}
+
+func redactQuery(req *http.Request, sensitive []string) string {
+	q := req.URL.Query()
+	for _, p := range append([]string{"apiKey"}, sensitive...) {
+		if _, ok := q[p]; ok {
+			q.Set(p, redacted)
+		}
+	}
+	return q.Encode()
+}
+
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	if h.Get("apiKey") != "" {
+		h.Set("apiKey", redacted)
+	}
+	return h
+}
+
+func truncate(body []byte, limit int) string {
+	if len(body) <= limit {
+		return string(body)
+	}
+	for limit > 0 && !utf8.RuneStart(body[limit]) {
+		limit--
+	}
+	return string(body[:limit]) + "…"
+}
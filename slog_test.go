@@ -0,0 +1,103 @@
+package airly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStructuredLoggingMiddleware(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/measurements/installation", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining-day", "99")
+		fmt.Fprint(w, mockMeasurementResponse)
+	})
+	mux.HandleFunc("/installations/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errorCode":"INSTALLATION_NOT_FOUND","message":"Not found"}`)
+	})
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client.Use(StructuredLoggingMiddleware(logger, SlogOptions{
+		SensitiveParams: []string{"installationId"},
+		BodyLimit:       10,
+	}))
+
+	if _, err := client.Measurement.ByID(context.Background(), NewByIDMeasurementOpts(6600)); err != nil {
+		t.Fatalf("Measurement.ByID: %v", err)
+	}
+	if _, err := client.Installation.ByID(context.Background(), 1); err == nil {
+		t.Fatal("Installation.ByID succeeded")
+	}
+
+	if strings.Contains(buf.String(), `"apiKey"`) && !strings.Contains(buf.String(), redacted) {
+		t.Errorf("api key logged: %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "6600") {
+		t.Errorf("sensitive param logged: %s", buf.String())
+	}
+
+	var records []map[string]interface{}
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r map[string]interface{}
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("decode log: %v", err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 2 {
+		t.Fatalf("logged %d records, want 2", len(records))
+	}
+
+	ok := records[0]
+	if ok["level"] != "INFO" || ok["status"] != float64(200) || ok["rateLimitRemaining"] != "99" || ok["path"] != "/measurements/installation" {
+		t.Errorf("success record %v", ok)
+	}
+	if body, _ := ok["body"].(string); len(body) != 10+len("…") || !strings.HasSuffix(body, "…") {
+		t.Errorf("body %q not truncated to 10 bytes", body)
+	}
+	header, _ := ok["header"].(map[string]interface{})
+	if got := fmt.Sprint(header["Apikey"]); got != "["+redacted+"]" {
+		t.Errorf("logged apiKey header %s", got)
+	}
+
+	failed := records[1]
+	if failed["level"] != "WARN" || failed["errorCode"] != "INSTALLATION_NOT_FOUND" {
+		t.Errorf("failure record %v", failed)
+	}
+}
+
+func TestStructuredLoggingMiddleware_notModified(t *testing.T) {
+	client, mux, teardown := setup()
+	defer teardown()
+	client.ConditionalRequests(NewMemoryCache(10, 0))
+
+	mux.HandleFunc("/meta/indexes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, mockIndexesResponse)
+	})
+
+	var buf bytes.Buffer
+	client.Use(StructuredLoggingMiddleware(slog.New(slog.NewJSONHandler(&buf, nil)), SlogOptions{}))
+	for i := 0; i < 2; i++ {
+		if _, err := client.Meta.Indexes(context.Background()); err != nil {
+			t.Fatalf("Meta.Indexes: %v", err)
+		}
+	}
+	if !strings.Contains(buf.String(), `"status":304`) || strings.Contains(buf.String(), `"level":"WARN"`) {
+		t.Errorf("revalidation logged as %s, want 304 at INFO", buf.String())
+	}
+}
@@ -0,0 +1,47 @@
+package units
+
+import (
+	"context"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+// Value is a measured value with its label and unit.
+type Value struct {
+	airly.Value
+	// Label and Unit are empty when the value has no measurement type.
+	Label string
+	Unit  string
+}
+
+// Catalog holds measurement types by name.
+type Catalog map[string]airly.MeasurementType
+
+// NewCatalog creates a Catalog of types.
+func NewCatalog(types []airly.MeasurementType) Catalog {
+	c := make(Catalog, len(types))
+	for _, t := range types {
+		c[t.Name] = t
+	}
+	return c
+}
+
+// LoadCatalog creates a Catalog of the measurement types returned by meta.
+// Their labels are in the language of the client.
+func LoadCatalog(ctx context.Context, meta airly.MetaAPI) (Catalog, error) {
+	types, err := meta.Measurements(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewCatalog(types), nil
+}
+
+// Enrich returns values with their labels and units.
+func (c Catalog) Enrich(values []airly.Value) []Value {
+	out := make([]Value, len(values))
+	for i, v := range values {
+		t := c[v.Name]
+		out[i] = Value{Value: v, Label: t.Label, Unit: t.Unit}
+	}
+	return out
+}
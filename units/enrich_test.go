@@ -0,0 +1,38 @@
+package units
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	meta := &airly.FakeMetaService{
+		MeasurementsFunc: func(ctx context.Context) ([]airly.MeasurementType, error) {
+			return []airly.MeasurementType{
+				{Name: "PM25", Label: "PM2.5", Unit: "µg/m³"},
+				{Name: "HUMIDITY", Label: "Wilgotność", Unit: "%"},
+			}, nil
+		},
+	}
+	catalog, err := LoadCatalog(context.Background(), meta)
+	if err != nil {
+		t.Fatalf("LoadCatalog returned error: %v", err)
+	}
+
+	got := catalog.Enrich([]airly.Value{
+		{Name: "PM25", Value: 12.5},
+		{Name: "HUMIDITY", Value: 60},
+		{Name: "NOISE", Value: 40},
+	})
+	want := []Value{
+		{Value: airly.Value{Name: "PM25", Value: 12.5}, Label: "PM2.5", Unit: "µg/m³"},
+		{Value: airly.Value{Name: "HUMIDITY", Value: 60}, Label: "Wilgotność", Unit: "%"},
+		{Value: airly.Value{Name: "NOISE", Value: 40}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Enrich returned %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package units
+
+import (
+	"strconv"
+	"strings"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+// separators are the number format of a language.
+type separators struct {
+	decimal, group string
+	// minGrouped is the number of integer digits from which they are grouped.
+	minGrouped int
+}
+
+var englishSeparators = separators{".", ",", 4}
+
+// languageSeparators of languages that do not format numbers like English.
+var languageSeparators = map[string]separators{
+	"bg": {",", "\u00a0", 5},
+	"cs": {",", "\u00a0", 4},
+	"de": {",", ".", 4},
+	"es": {",", ".", 5},
+	"fr": {",", "\u202f", 4},
+	"hu": {",", "\u00a0", 4},
+	"it": {",", ".", 4},
+	"lt": {",", "\u00a0", 4},
+	"nl": {",", ".", 4},
+	"pl": {",", "\u00a0", 5},
+	"pt": {",", ".", 4},
+	"ro": {",", ".", 4},
+	"ru": {",", "\u00a0", 4},
+	"sk": {",", "\u00a0", 4},
+	"sl": {",", ".", 4},
+	"uk": {",", "\u00a0", 4},
+}
+
+// FormatNumber formats v with decimals digits after the decimal separator
+// of the language lang, e.g. "pl" or "en-GB".
+func FormatNumber(v float64, decimals int, lang airly.Language) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+
+	sep, ok := languageSeparators[baseLanguage(lang)]
+	if !ok {
+		sep = englishSeparators
+	}
+	if len(integer) >= sep.minGrouped {
+		var b strings.Builder
+		for i, r := range integer {
+			if i > 0 && (len(integer)-i)%3 == 0 {
+				b.WriteString(sep.group)
+			}
+			b.WriteRune(r)
+		}
+		integer = b.String()
+	}
+
+	if fraction == "" {
+		return sign + integer
+	}
+	return sign + integer + sep.decimal + fraction
+}
+
+// Format formats the value with its unit in the language lang,
+// e.g. "12,5 µg/m³" in Polish.
+func (v Value) Format(decimals int, lang airly.Language) string {
+	s := FormatNumber(v.Value.Value, decimals, lang)
+	switch v.Unit {
+	case "":
+		return s
+	case "%", "°":
+		return s + v.Unit
+	default:
+		return s + " " + v.Unit
+	}
+}
+
+func baseLanguage(lang airly.Language) string {
+	s := strings.ToLower(string(lang))
+	if i := strings.IndexAny(s, "-_"); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+package units
+
+import (
+	"testing"
+
+	airly "github.com/lsjurczak/go-airly"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		v        float64
+		decimals int
+		lang     airly.Language
+		want     string
+	}{
+		{12.345, 1, "en", "12.3"},
+		{12.345, 1, "pl", "12,3"},
+		{1013.25, 0, "en", "1,013"},
+		{1013.25, 0, "pl", "1013"},
+		{12345.6, 1, "pl", "12\u00a0345,6"},
+		{12345.5, 1, "de-DE", "12.345,5"},
+		{1013.4, 1, "de", "1.013,4"},
+		{12345.5, 1, "fr", "12\u202f345,5"},
+		{1013.25, 0, "fr-CA", "1\u202f013"},
+		{-1234567, 0, "en_US", "-1,234,567"},
+		{5, 2, "", "5.00"},
+	}
+	for _, tt := range tests {
+		if got := FormatNumber(tt.v, tt.decimals, tt.lang); got != tt.want {
+			t.Errorf("FormatNumber(%v, %d, %q) = %q, want %q", tt.v, tt.decimals, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestValue_Format(t *testing.T) {
+	tests := []struct {
+		v    Value
+		lang airly.Language
+		want string
+	}{
+		{Value{Value: airly.Value{Value: 12.5}, Unit: "µg/m³"}, "pl", "12,5 µg/m³"},
+		{Value{Value: airly.Value{Value: 61}, Unit: "%"}, "en", "61.0%"},
+		{Value{Value: airly.Value{Value: 3}}, "en", "3.0"},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Format(1, tt.lang); got != tt.want {
+			t.Errorf("Format(1, %q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+}
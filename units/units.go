@@ -0,0 +1,126 @@
+// Package units converts and formats the values of Airly measurements
+// and joins them with their labels and units from MetaService.Measurements.
+package units
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// gasConstant is the molar gas constant in J/(mol·K).
+	gasConstant = 8.314462618
+	zeroCelsius = 273.15
+	hPaPerInHg  = 33.8638866667
+	metersPerMi = 1609.344
+)
+
+// MolarMass is the molar mass in g/mol of the gases measured by Airly.
+var MolarMass = map[string]float64{
+	"NO2": 46.0055,
+	"O3":  47.9982,
+	"SO2": 64.066,
+	"CO":  28.0101,
+	"NH3": 17.0305,
+	"H2S": 34.081,
+}
+
+// MicrogramsToPPB converts the concentration of the gas named name,
+// e.g. "NO2", from µg/m³ to ppb at the temperature in °C and pressure
+// in hPa of the measurement.
+func MicrogramsToPPB(name string, ugm3, celsius, hPa float64) (float64, error) {
+	m, ok := MolarMass[name]
+	if !ok {
+		return 0, fmt.Errorf("units: unknown gas %q", name)
+	}
+	return ugm3 * molarVolume(celsius, hPa) / m, nil
+}
+
+// PPBToMicrograms converts the concentration of the gas named name
+// from ppb to µg/m³, see MicrogramsToPPB.
+func PPBToMicrograms(name string, ppb, celsius, hPa float64) (float64, error) {
+	m, ok := MolarMass[name]
+	if !ok {
+		return 0, fmt.Errorf("units: unknown gas %q", name)
+	}
+	return ppb * m / molarVolume(celsius, hPa), nil
+}
+
+// molarVolume returns the volume of a mole of gas in liters.
+func molarVolume(celsius, hPa float64) float64 {
+	return gasConstant * (celsius + zeroCelsius) / (hPa * 100) * 1000
+}
+
+// CelsiusToFahrenheit converts a temperature from °C to °F.
+func CelsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// FahrenheitToCelsius converts a temperature from °F to °C.
+func FahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// HPaToInHg converts a pressure from hPa to inches of mercury.
+func HPaToInHg(hPa float64) float64 {
+	return hPa / hPaPerInHg
+}
+
+// InHgToHPa converts a pressure from inches of mercury to hPa.
+func InHgToHPa(inHg float64) float64 {
+	return inHg * hPaPerInHg
+}
+
+// SpeedUnit is a unit of wind speed.
+type SpeedUnit int
+
+const (
+	MetersPerSecond SpeedUnit = iota
+	// KilometersPerHour is the unit of WIND_SPEED in the Airly API.
+	KilometersPerHour
+	MilesPerHour
+)
+
+// metersPerSecond is the speed of one unit in m/s.
+func (u SpeedUnit) metersPerSecond() float64 {
+	switch u {
+	case KilometersPerHour:
+		return 1000.0 / 3600
+	case MilesPerHour:
+		return metersPerMi / 3600
+	default:
+		return 1
+	}
+}
+
+// ConvertSpeed converts a speed from one unit to another.
+func ConvertSpeed(v float64, from, to SpeedUnit) float64 {
+	return v * from.metersPerSecond() / to.metersPerSecond()
+}
+
+var compassPoints = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// CompassPoint returns the closest of the 16 compass points, e.g. "NNE",
+// to the bearing in degrees, such as WIND_BEARING.
+func CompassPoint(bearing float64) string {
+	b := math.Mod(bearing, 360)
+	if b < 0 {
+		b += 360
+	}
+	i := int(math.Round(b/22.5)) % len(compassPoints)
+	return compassPoints[i]
+}
+
+// Bearing returns the bearing in degrees of a compass point, e.g. "NNE".
+func Bearing(point string) (float64, error) {
+	for i, p := range compassPoints {
+		if strings.EqualFold(p, point) {
+			return float64(i) * 22.5, nil
+		}
+	}
+	return 0, fmt.Errorf("units: unknown compass point %q", point)
+}
@@ -0,0 +1,88 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestMicrogramsToPPB(t *testing.T) {
+	// At 25 °C and 1013.25 hPa, 1 ppb of NO2 is 1.88 µg/m³.
+	ppb, err := MicrogramsToPPB("NO2", 188, 25, 1013.25)
+	if err != nil || !near(ppb, 99.97, 0.01) {
+		t.Errorf("MicrogramsToPPB(NO2) = %v, %v, want 99.97", ppb, err)
+	}
+	ugm3, err := PPBToMicrograms("O3", 50, 20, 1013.25)
+	if err != nil || !near(ugm3, 99.77, 0.01) {
+		t.Errorf("PPBToMicrograms(O3) = %v, %v, want 99.77", ugm3, err)
+	}
+	if _, err := MicrogramsToPPB("PM25", 10, 20, 1013.25); err == nil {
+		t.Error("MicrogramsToPPB returned nil error for PM25")
+	}
+}
+
+func TestTemperatureAndPressure(t *testing.T) {
+	if got := CelsiusToFahrenheit(-40); got != -40 {
+		t.Errorf("CelsiusToFahrenheit(-40) = %v, want -40", got)
+	}
+	if got := FahrenheitToCelsius(212); got != 100 {
+		t.Errorf("FahrenheitToCelsius(212) = %v, want 100", got)
+	}
+	if got := HPaToInHg(1013.25); !near(got, 29.92, 0.005) {
+		t.Errorf("HPaToInHg(1013.25) = %v, want 29.92", got)
+	}
+	if got := InHgToHPa(HPaToInHg(1000)); !near(got, 1000, 1e-9) {
+		t.Errorf("InHgToHPa(HPaToInHg(1000)) = %v, want 1000", got)
+	}
+}
+
+func TestConvertSpeed(t *testing.T) {
+	tests := []struct {
+		v        float64
+		from, to SpeedUnit
+		want     float64
+	}{
+		{10, MetersPerSecond, KilometersPerHour, 36},
+		{36, KilometersPerHour, MetersPerSecond, 10},
+		{100, KilometersPerHour, MilesPerHour, 62.137},
+		{1, MilesPerHour, MetersPerSecond, 0.44704},
+	}
+	for _, tt := range tests {
+		if got := ConvertSpeed(tt.v, tt.from, tt.to); !near(got, tt.want, 1e-3) {
+			t.Errorf("ConvertSpeed(%v, %d, %d) = %v, want %v", tt.v, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestCompassPoint(t *testing.T) {
+	tests := []struct {
+		bearing float64
+		want    string
+	}{
+		{0, "N"},
+		{11.24, "N"},
+		{11.26, "NNE"},
+		{90, "E"},
+		{200, "SSW"},
+		{350, "N"},
+		{-90, "W"},
+		{720 + 45, "NE"},
+	}
+	for _, tt := range tests {
+		if got := CompassPoint(tt.bearing); got != tt.want {
+			t.Errorf("CompassPoint(%v) = %q, want %q", tt.bearing, got, tt.want)
+		}
+	}
+}
+
+func TestBearing(t *testing.T) {
+	if got, err := Bearing("wsw"); err != nil || got != 247.5 {
+		t.Errorf("Bearing(wsw) = %v, %v, want 247.5", got, err)
+	}
+	if _, err := Bearing("X"); err == nil {
+		t.Error("Bearing(X) returned nil error")
+	}
+}